@@ -0,0 +1,74 @@
+// gobox-audit 校验审计日志的哈希链
+//
+// 用法:
+//
+//	gobox-audit verify [-key KEY | -key-file FILE] [-strict] <audit.log>
+//
+// 最早的记录不是第 1 条时输出警告，-strict 时按校验失败处理。
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/52debug/go-box/log/logmgr/audit"
+)
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "verify" {
+		usage()
+	}
+
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	key := fs.String("key", "", "HMAC 密钥")
+	keyFile := fs.String("key-file", "", "从文件读取 HMAC 密钥(去除首尾空白)")
+	strict := fs.Bool("strict", false, "最早的记录不是第 1 条时校验失败")
+	fs.Usage = usage
+	_ = fs.Parse(os.Args[2:])
+	if fs.NArg() != 1 {
+		usage()
+	}
+
+	var k []byte
+	switch {
+	case *keyFile != "":
+		data, err := os.ReadFile(*keyFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "读取密钥失败:", err)
+			os.Exit(2)
+		}
+		k = []byte(strings.TrimSpace(string(data)))
+	case *key != "":
+		k = []byte(*key)
+	}
+
+	res, err := audit.Verify(fs.Arg(0), k)
+	if err != nil {
+		var broken *audit.BrokenLinkError
+		if errors.As(err, &broken) {
+			fmt.Fprintln(os.Stderr, "校验失败:", broken)
+			os.Exit(1)
+		}
+		fmt.Fprintln(os.Stderr, "校验出错:", err)
+		os.Exit(2)
+	}
+	if res.Records == 0 {
+		fmt.Println("没有审计记录")
+		return
+	}
+	if res.Anchored {
+		fmt.Fprintf(os.Stderr, "警告: 最早的记录为 seq %d，之前的记录无法校验: 旧文件被轮转清理，或文件开头被截断\n", res.FirstSeq)
+		if *strict {
+			os.Exit(1)
+		}
+	}
+	fmt.Printf("校验通过: %d 个文件，%d 条记录 (seq %d-%d)\n", len(res.Files), res.Records, res.FirstSeq, res.LastSeq)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "用法: gobox-audit verify [-key KEY | -key-file FILE] [-strict] <audit.log>")
+	os.Exit(2)
+}
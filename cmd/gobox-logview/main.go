@@ -0,0 +1,235 @@
+// gobox-logview 将 JSON 日志文件还原为带颜色的控制台格式，或按条件查询日志
+//
+// 用法:
+//
+//	gobox-logview [-f] [-rotated] [-theme NAME] [-template TPL] [-time-format F] [-tz ZONE] [file ...]
+//	gobox-logview query [-since T] [-until T] [-level L] [-msg RE] [-where COND]... [-format json|table] <file>
+//
+// 未指定文件时读取标准输入，.gz 和 .zst 文件自动解压，无法解析为 JSON 的行原样输出。
+// query 在文件及其所有备份中查询，如:
+//
+//	gobox-logview query -level error -since 10:00 -until 10:15 -where module=db -where user_id=42 app.log
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/52debug/go-box/log/logmgr"
+	"github.com/mattn/go-colorable"
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "query" {
+		if err := runQuery(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	follow := flag.Bool("f", false, "输出最后一个文件后持续读取新内容，文件轮转后继续读取新文件")
+	rotated := flag.Bool("rotated", false, "同时按时间顺序读取文件的 lumberjack 备份")
+	theme := flag.String("theme", "", "配色主题: default, dark, light, monochrome")
+	template := flag.String("template", "", "行格式模板，默认 "+logmgr.DefaultTemplate)
+	timeFormat := flag.String("time-format", "", "日志文件使用的时间格式(LogConfig.TimeFormat)")
+	timeZone := flag.String("tz", "", "显示时区: local(默认), utc 或 IANA 名称")
+	schema := flag.String("schema", "", "日志文件使用的字段映射(LogConfig.Schema): native, ecs, gcp")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "用法: gobox-logview [选项] [file ...]")
+		fmt.Fprintln(os.Stderr, "      gobox-logview query [选项] <file>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	config := logmgr.LogConfig{Theme: *theme, Template: *template, TimeFormat: *timeFormat, TimeZone: *timeZone, Schema: *schema}
+	v, err := newViewer(config, colorable.NewColorableStdout())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if flag.NArg() == 0 {
+		err = v.copy(os.Stdin)
+	} else {
+		err = v.view(flag.Args(), *rotated, *follow)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// viewer 解析 JSON 日志行并按模板输出
+type viewer struct {
+	out       *bufio.Writer
+	parser    *logmgr.EntryParser
+	formatter *logmgr.Formatter
+	buf       []byte
+	interval  time.Duration   // follow 检查新内容的间隔
+	done      <-chan struct{} // 关闭时 follow 返回，为 nil 时一直读取
+}
+
+func newViewer(config logmgr.LogConfig, out io.Writer) (*viewer, error) {
+	parser, err := config.NewEntryParser()
+	if err != nil {
+		return nil, err
+	}
+	formatter, err := config.NewFormatter()
+	if err != nil {
+		return nil, err
+	}
+	return &viewer{out: bufio.NewWriter(out), parser: parser, formatter: formatter, interval: 200 * time.Millisecond}, nil
+}
+
+// view 依次输出 paths，follow 时持续读取最后一个文件
+func (v *viewer) view(paths []string, rotated, follow bool) error {
+	for i, path := range paths {
+		files := []string{path}
+		if rotated {
+			var err error
+			if files, err = logmgr.LogFiles(path); err != nil {
+				return err
+			}
+		}
+		last := i == len(paths)-1
+		for j, file := range files {
+			if follow && last && j == len(files)-1 {
+				return v.follow(file)
+			}
+			if err := v.copyFile(file); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v *viewer) copyFile(path string) error {
+	f, err := logmgr.OpenLogFile(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return v.copy(f)
+}
+
+// copy 输出 r 中的所有行
+func (v *viewer) copy(r io.Reader) error {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			v.writeLine(line)
+		}
+		if err == io.EOF {
+			return v.out.Flush()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// follow 持续读取 path，文件被轮转(重命名后重新创建)时读完旧文件再从新文件开头读取，被截断时从头读取
+func (v *viewer) follow(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { f.Close() }()
+	br := bufio.NewReader(f)
+	var partial []byte
+	for {
+		line, err := br.ReadBytes('\n')
+		if err == nil {
+			v.writeLine(append(partial, line...))
+			partial = partial[:0]
+			continue
+		}
+		if err != io.EOF {
+			return err
+		}
+		// 行尚未写完，等待剩余部分
+		partial = append(partial, line...)
+		if err := v.out.Flush(); err != nil {
+			return err
+		}
+		select {
+		case <-v.done:
+			return nil
+		case <-time.After(v.interval):
+		}
+
+		opened, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		current, err := os.Stat(path)
+		if err != nil {
+			// 轮转过程中文件可能暂时不存在
+			continue
+		}
+		if !os.SameFile(opened, current) {
+			// 旧文件可能还有未读的内容
+			if _, err := br.Peek(1); err == nil {
+				continue
+			}
+			if len(partial) > 0 {
+				v.writeLine(partial)
+				partial = partial[:0]
+			}
+			nf, err := os.Open(path)
+			if err != nil {
+				continue
+			}
+			f.Close()
+			f = nf
+			br.Reset(f)
+			continue
+		}
+		if offset, err := f.Seek(0, io.SeekCurrent); err == nil && current.Size() < offset-int64(br.Buffered()) {
+			// 文件被截断
+			f.Seek(0, io.SeekStart)
+			br.Reset(f)
+			partial = partial[:0]
+		}
+	}
+}
+
+// writeLine 输出一行日志，无法解析的行原样输出
+func (v *viewer) writeLine(line []byte) {
+	line = bytes.TrimRight(line, "\r\n")
+	if len(line) == 0 {
+		return
+	}
+	e, err := v.parser.Parse(line)
+	if err != nil {
+		v.out.Write(line)
+		v.out.WriteByte('\n')
+		return
+	}
+
+	rec := logmgr.Record{Time: e.Time, Level: e.Level, Message: e.Message}
+	rec.File, rec.Line = e.CallerLocation()
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		rec.Fields = v.formatter.AppendField(rec.Fields, k, logmgr.FieldString(e.Fields[k]))
+	}
+
+	v.buf = v.formatter.Append(v.buf[:0], &rec)
+	if e.Stack != "" {
+		v.buf = append(append(v.buf, e.Stack...), '\n')
+	}
+	v.out.Write(v.buf)
+}
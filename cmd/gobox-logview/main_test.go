@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/52debug/go-box/log/logmgr"
+)
+
+// syncBuffer 可以在 follow 写入时并发读取的缓冲
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func testViewer(t *testing.T, out io.Writer) *viewer {
+	t.Helper()
+	v, err := newViewer(logmgr.LogConfig{Theme: "monochrome", Template: "{level} {message}"}, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// bold 返回 monochrome 主题下加粗的级别
+func bold(s string) string {
+	return "\x1b[1m" + s + "\x1b[0m"
+}
+
+func TestCopy(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "json",
+			input: `{"level":"info","msg":"a"}` + "\n" + `{"level":"error","msg":"b"}` + "\n",
+			want:  "INFO a\n" + bold("ERROR") + " b\n",
+		},
+		{
+			// 无法解析为 JSON 的行原样输出
+			name:  "raw",
+			input: "plain text\n" + `{"level":"warn","msg":"c"}` + "\n{broken\n",
+			want:  "plain text\n" + bold("WARN") + " c\n{broken\n",
+		},
+		{
+			name:  "crlf and blank lines",
+			input: `{"level":"info","msg":"a"}` + "\r\n\n\r\nplain\r\n",
+			want:  "INFO a\nplain\n",
+		},
+		{
+			name:  "no trailing newline",
+			input: `{"level":"info","msg":"a"}`,
+			want:  "INFO a\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 通过管道逐字节写入，行被拆分到多次读取中
+			pr, pw := io.Pipe()
+			go func() {
+				for i := 0; i < len(tt.input); i++ {
+					pw.Write([]byte{tt.input[i]})
+				}
+				pw.Close()
+			}()
+			var out bytes.Buffer
+			if err := testViewer(t, &out).copy(pr); err != nil {
+				t.Fatal(err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Fatalf("输出 %q，期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFollow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { f.Close() }()
+	write := func(s string) {
+		t.Helper()
+		if _, err := f.WriteString(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`{"level":"info","msg":"` + strings.Repeat("x", 100) + `"}` + "\n")
+
+	var out syncBuffer
+	v := testViewer(t, &out)
+	v.interval = 10 * time.Millisecond
+	done := make(chan struct{})
+	v.done = done
+	errc := make(chan error, 1)
+	go func() { errc <- v.follow(path) }()
+
+	want := "INFO " + strings.Repeat("x", 100) + "\n"
+	waitOutput(t, &out, want)
+
+	// 写了一半的行等待剩余部分
+	write(`{"level":"info","msg":"par`)
+	time.Sleep(50 * time.Millisecond)
+	write(`tial"}` + "\nplain\n")
+	want += "INFO partial\nplain\n"
+	waitOutput(t, &out, want)
+
+	// 截断后从头读取
+	if err := f.Truncate(0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	write(`{"level":"warn","msg":"t"}` + "\n")
+	want += bold("WARN") + " t\n"
+	waitOutput(t, &out, want)
+
+	// 轮转: 旧文件未读的内容输出后从新文件开头读取
+	write(`{"level":"info","msg":"old"}` + "\n")
+	f.Close()
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if f, err = os.Create(path); err != nil {
+		t.Fatal(err)
+	}
+	write(`{"level":"error","msg":"new"}` + "\n")
+	want += "INFO old\n" + bold("ERROR") + " new\n"
+	waitOutput(t, &out, want)
+
+	close(done)
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// waitOutput 等待输出等于 want
+func waitOutput(t *testing.T, out *syncBuffer, want string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for out.String() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("输出 %q，期望 %q", out.String(), want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
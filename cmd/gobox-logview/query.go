@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/52debug/go-box/log/logmgr"
+	"github.com/52debug/go-box/log/logmgr/logquery"
+)
+
+// multiFlag 可重复指定的参数
+type multiFlag []string
+
+func (f *multiFlag) String() string { return strings.Join(*f, ",") }
+
+func (f *multiFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
+
+// runQuery 执行 query 子命令
+func runQuery(args []string) error {
+	fs := flag.NewFlagSet("query", flag.ExitOnError)
+	since := fs.String("since", "", "起始时间(含)，如 10:00、2006-01-02 15:04:05、RFC 3339 或 -15m")
+	until := fs.String("until", "", "结束时间(不含)，格式同 -since")
+	level := fs.String("level", "", "最低级别，如 error")
+	msg := fs.String("msg", "", "消息匹配的正则")
+	format := fs.String("format", "json", "输出格式: json(原始行), table")
+	limit := fs.Int("limit", 0, "最多输出的记录数，0 表示不限")
+	timeFormat := fs.String("time-format", "", "日志文件使用的时间格式(LogConfig.TimeFormat)")
+	timeZone := fs.String("tz", "", "日志文件使用的时区(LogConfig.TimeZone)")
+	schema := fs.String("schema", "", "日志文件使用的字段映射(LogConfig.Schema): native, ecs, gcp")
+	var where multiFlag
+	fs.Var(&where, "where", "字段条件，可重复: key=value, key!=value, key>n, key>=n, key<n, key<=n, key~regex")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "用法: gobox-logview query [选项] <日志文件路径>")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	config := logmgr.LogConfig{TimeFormat: *timeFormat, TimeZone: *timeZone, Schema: *schema}
+	parser, err := config.NewEntryParser()
+	if err != nil {
+		return err
+	}
+	timeEnc, _ := config.NewTimeEncoder()
+	now := time.Now().In(timeEnc.Location())
+
+	var q logquery.Query
+	if *since != "" {
+		if q.Since, err = logquery.ParseTime(*since, now); err != nil {
+			return err
+		}
+	}
+	if *until != "" {
+		if q.Until, err = logquery.ParseTime(*until, now); err != nil {
+			return err
+		}
+	}
+	if *level != "" {
+		l, err := logmgr.ParseLevel(*level)
+		if err != nil {
+			return err
+		}
+		q.Level = &l
+	}
+	if *msg != "" {
+		if q.Message, err = regexp.Compile(*msg); err != nil {
+			return fmt.Errorf("消息正则无效: %w", err)
+		}
+	}
+	for _, w := range where {
+		p, err := logquery.ParsePredicate(w)
+		if err != nil {
+			return err
+		}
+		q.Fields = append(q.Fields, p)
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	var emit func(m *logquery.Match)
+	switch *format {
+	case "json":
+		emit = func(m *logquery.Match) {
+			out.Write(m.Raw)
+			out.WriteByte('\n')
+		}
+	case "table":
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		defer tw.Flush()
+		fmt.Fprintln(tw, "TIME\tLEVEL\tCALLER\tMESSAGE\tFIELDS")
+		emit = func(m *logquery.Match) {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", m.Time.In(timeEnc.Location()).Format(logmgr.DefaultTimeLayout),
+				m.Level.CapitalString(), m.Caller, tableCell(m.Message), tableFields(m.Fields))
+		}
+	default:
+		return fmt.Errorf("未知的输出格式 %q", *format)
+	}
+
+	n := 0
+	return logquery.Run(fs.Arg(0), q, parser, func(m *logquery.Match) bool {
+		emit(m)
+		n++
+		return *limit <= 0 || n < *limit
+	})
+}
+
+// tableFields 将字段按名称排序输出为 key=value
+func tableFields(fields map[string]interface{}) string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		v := logmgr.FieldString(fields[k])
+		if strings.ContainsAny(v, " \t\"") {
+			data, _ := json.Marshal(v)
+			v = string(data)
+		}
+		b.WriteString(k + "=" + v)
+	}
+	return tableCell(b.String())
+}
+
+// tableCell 去掉会破坏表格对齐的换行和制表符
+func tableCell(s string) string {
+	return strings.NewReplacer("\n", "\\n", "\t", " ").Replace(s)
+}
@@ -0,0 +1,143 @@
+package errutil
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// 记录的最大栈帧数
+const maxStackDepth = 32
+
+// stackError 记录了创建位置调用栈的错误
+type stackError struct {
+	msg   string // 说明，为空时直接使用 err 的信息
+	err   error
+	stack []uintptr
+}
+
+func (e *stackError) Error() string {
+	switch {
+	case e.err == nil:
+		return e.msg
+	case e.msg == "":
+		return e.err.Error()
+	}
+	return e.msg + ": " + e.err.Error()
+}
+
+func (e *stackError) Unwrap() error {
+	return e.err
+}
+
+// StackTrace 返回创建错误时的调用栈，格式与 logmgr.Stacktrace 相同，由 logmgr.Err 输出
+func (e *stackError) StackTrace() string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(e.stack)
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" && frame.Function != "runtime.goexit" {
+			if b.Len() > 0 {
+				b.WriteByte('\n')
+			}
+			b.WriteString(frame.Function)
+			b.WriteString("\n\t")
+			b.WriteString(frame.File)
+			b.WriteByte(':')
+			b.WriteString(strconv.Itoa(frame.Line))
+		}
+		if !more {
+			return b.String()
+		}
+	}
+}
+
+// callers 返回调用 errutil 导出函数的位置开始的调用栈
+func callers() []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(3, pcs)
+	return pcs[:n]
+}
+
+// New 创建记录调用栈的错误
+func New(msg string) error {
+	return &stackError{msg: msg, stack: callers()}
+}
+
+// Errorf 按格式创建记录调用栈的错误，支持 %w
+func Errorf(format string, args ...interface{}) error {
+	return &stackError{err: fmt.Errorf(format, args...), stack: callers()}
+}
+
+// Wrap 为错误添加说明并记录调用栈，err 为 nil 时返回 nil
+func Wrap(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+	return &stackError{msg: msg, err: err, stack: callers()}
+}
+
+// WithStack 为错误记录调用栈，err 为 nil 或已记录调用栈时原样返回
+func WithStack(err error) error {
+	if err == nil || StackTrace(err) != "" {
+		return err
+	}
+	return &stackError{err: err, stack: callers()}
+}
+
+// StackTrace 返回错误链中最早记录的调用栈，没有时返回空串
+func StackTrace(err error) string {
+	var stack string
+	for err != nil {
+		if st, ok := err.(interface{ StackTrace() string }); ok {
+			stack = st.StackTrace()
+		}
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+		err = u.Unwrap()
+	}
+	return stack
+}
+
+// fieldsError 携带日志字段的错误
+type fieldsError struct {
+	err    error
+	fields map[string]interface{}
+}
+
+func (e *fieldsError) Error() string {
+	return e.err.Error()
+}
+
+func (e *fieldsError) Unwrap() error {
+	return e.err
+}
+
+// LogFields 返回错误携带的日志字段，由 logmgr.Err 输出
+func (e *fieldsError) LogFields() map[string]interface{} {
+	return e.fields
+}
+
+// badKey 与 slog 一致，缺少值的键记录在该字段中
+const badKey = "!BADKEY"
+
+// WithFields 为错误附加日志字段，参数为交替的键和值，err 为 nil 时返回 nil；
+// 参数个数为奇数时最后一个键记录为 !BADKEY 字段
+//
+//	return errutil.WithFields(err, "user_id", id, "order", orderNo)
+func WithFields(err error, keysAndValues ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	fields := make(map[string]interface{}, (len(keysAndValues)+1)/2)
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		fields[fmt.Sprint(keysAndValues[i])] = keysAndValues[i+1]
+	}
+	if len(keysAndValues)%2 == 1 {
+		fields[badKey] = keysAndValues[len(keysAndValues)-1]
+	}
+	return &fieldsError{err: err, fields: fields}
+}
@@ -0,0 +1,40 @@
+package errutil
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestWithFields(t *testing.T) {
+	base := errors.New("boom")
+	tests := []struct {
+		name string
+		args []interface{}
+		want map[string]interface{}
+	}{
+		{name: "pairs", args: []interface{}{"user_id", 1, "order", "A1"}, want: map[string]interface{}{"user_id": 1, "order": "A1"}},
+		{name: "key not string", args: []interface{}{1, true}, want: map[string]interface{}{"1": true}},
+		{name: "odd", args: []interface{}{"user_id", 1, "order"}, want: map[string]interface{}{"user_id": 1, "!BADKEY": "order"}},
+		{name: "single", args: []interface{}{"order"}, want: map[string]interface{}{"!BADKEY": "order"}},
+		{name: "empty", want: map[string]interface{}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := WithFields(base, tt.args...)
+			if !errors.Is(err, base) {
+				t.Fatalf("%v 未包装原错误", err)
+			}
+			var fe interface{ LogFields() map[string]interface{} }
+			if !errors.As(err, &fe) {
+				t.Fatalf("%T 没有 LogFields", err)
+			}
+			if got := fe.LogFields(); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("字段 %v，期望 %v", got, tt.want)
+			}
+		})
+	}
+	if WithFields(nil, "k", "v") != nil {
+		t.Fatal("err 为 nil 时应返回 nil")
+	}
+}
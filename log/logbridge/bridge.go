@@ -0,0 +1,40 @@
+package logbridge
+
+import (
+	"log/slog"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// InstallSlog 将 zap、zerolog 的全局 logger 以及标准库 log 路由到 slog 处理器 h，
+// 同时把 h 设置为 slog 的默认处理器
+func InstallSlog(h slog.Handler) {
+	slog.SetDefault(slog.New(h))
+	zap.ReplaceGlobals(zap.New(ZapCoreFromSlog(h), zap.AddCaller()))
+	// 级别由 h 判断
+	zerolog.SetGlobalLevel(zerolog.TraceLevel)
+	log.Logger = ZerologFromSlog(h)
+}
+
+// InstallZap 将 slog、zerolog 的全局 logger 以及标准库 log 路由到 zap logger，
+// 同时把 logger 设置为 zap 的全局 logger。stack 为 logger 通过 zap.AddStacktrace 设置的级别，
+// 路由过来的记录达到该级别时同样附加调用栈，为 nil 时不附加
+func InstallZap(logger *zap.Logger, stack zapcore.LevelEnabler) {
+	zap.ReplaceGlobals(logger)
+	h := SlogHandlerFromZapStacktrace(logger.Core(), stack)
+	slog.SetDefault(slog.New(h))
+	zerolog.SetGlobalLevel(zerolog.TraceLevel)
+	log.Logger = ZerologFromSlog(h)
+}
+
+// InstallZerolog 将 slog、zap 的全局 logger 以及标准库 log 路由到 zerolog logger。
+// logger 设置为 zerolog 的全局 logger；h 为其他日志库使用的处理器，
+// 通常由 SlogHandlerFromZerolog 创建
+func InstallZerolog(logger zerolog.Logger, h slog.Handler) {
+	log.Logger = logger
+	slog.SetDefault(slog.New(h))
+	zap.ReplaceGlobals(zap.New(ZapCoreFromSlog(h), zap.AddCaller()))
+}
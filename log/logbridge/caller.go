@@ -0,0 +1,64 @@
+package logbridge
+
+import (
+	"runtime"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// 查找 zap 调用位置时跳过的日志库内部栈帧
+var zapSkipPrefixes = []string{
+	"github.com/52debug/go-box/log/logbridge.",
+	"go.uber.org/zap",
+}
+
+// 记录堆栈时跳过的日志库内部栈帧
+var stackSkipPrefixes = []string{
+	"github.com/52debug/go-box/log/logbridge.",
+	"github.com/52debug/go-box/log/zaplogmgr.",
+	"log/slog.",
+	"log.",
+	"go.uber.org/zap",
+	"github.com/rs/zerolog",
+}
+
+// findPC 返回调用栈中第一个满足 match 的返回地址
+//
+// 使用 runtime.Callers 得到的原始地址而不是 Frame.PC，
+// slog 通过 runtime.CallersFrames 解析时才能得到正确的行号(包括内联的情况)。
+func findPC(match func(frame runtime.Frame) bool) uintptr {
+	var pcs [64]uintptr
+	n := runtime.Callers(3, pcs[:])
+	for _, pc := range pcs[:n] {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		if match(frame) {
+			return pc
+		}
+	}
+	return 0
+}
+
+// callerPC 返回跳过日志库内部栈帧后的第一个调用者
+func callerPC(skipPrefixes []string) uintptr {
+	return findPC(func(frame runtime.Frame) bool {
+		for _, p := range skipPrefixes {
+			if strings.HasPrefix(frame.Function, p) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// zapCallerPC 返回 zap 记录的调用位置对应的返回地址，
+// 找不到时(如栈帧超出范围)退回到跳过日志库内部栈帧后的第一个调用者
+func zapCallerPC(caller zapcore.EntryCaller) uintptr {
+	pc := findPC(func(frame runtime.Frame) bool {
+		return frame.File == caller.File && frame.Line == caller.Line
+	})
+	if pc == 0 {
+		pc = callerPC(zapSkipPrefixes)
+	}
+	return pc
+}
@@ -0,0 +1,87 @@
+package logbridge
+
+import (
+	"log/slog"
+
+	"github.com/52debug/go-box/log/logmgr"
+	"github.com/rs/zerolog"
+	"go.uber.org/zap/zapcore"
+)
+
+// zapToSlog 将 zap 级别转换为 slog 级别
+func zapToSlog(l zapcore.Level) slog.Level {
+	switch l {
+	case zapcore.DebugLevel:
+		return slog.LevelDebug
+	case zapcore.InfoLevel:
+		return slog.LevelInfo
+	case zapcore.WarnLevel:
+		return slog.LevelWarn
+	case zapcore.ErrorLevel:
+		return slog.LevelError
+	case zapcore.DPanicLevel, zapcore.PanicLevel:
+		return logmgr.SlogLevelPanic
+	case zapcore.FatalLevel:
+		return logmgr.SlogLevelFatal
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// slogToZap 将 slog 级别转换为 zap 级别，trace 按 debug 处理
+func slogToZap(l slog.Level) zapcore.Level {
+	switch logmgr.LevelFromSlog(l) {
+	case logmgr.TraceLevel, logmgr.DebugLevel:
+		return zapcore.DebugLevel
+	case logmgr.WarnLevel:
+		return zapcore.WarnLevel
+	case logmgr.ErrorLevel:
+		return zapcore.ErrorLevel
+	case logmgr.PanicLevel:
+		return zapcore.PanicLevel
+	case logmgr.FatalLevel:
+		return zapcore.FatalLevel
+	default:
+		return zapcore.InfoLevel
+	}
+}
+
+// zerologToSlog 将 zerolog 级别转换为 slog 级别
+func zerologToSlog(l zerolog.Level) slog.Level {
+	switch l {
+	case zerolog.TraceLevel:
+		return logmgr.SlogLevelTrace
+	case zerolog.DebugLevel:
+		return slog.LevelDebug
+	case zerolog.WarnLevel:
+		return slog.LevelWarn
+	case zerolog.ErrorLevel:
+		return slog.LevelError
+	case zerolog.PanicLevel:
+		return logmgr.SlogLevelPanic
+	case zerolog.FatalLevel:
+		return logmgr.SlogLevelFatal
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// slogToZerolog 将 slog 级别转换为 zerolog 级别
+func slogToZerolog(l slog.Level) zerolog.Level {
+	switch logmgr.LevelFromSlog(l) {
+	case logmgr.TraceLevel:
+		return zerolog.TraceLevel
+	case logmgr.DebugLevel:
+		return zerolog.DebugLevel
+	case logmgr.WarnLevel:
+		return zerolog.WarnLevel
+	case logmgr.ErrorLevel:
+		return zerolog.ErrorLevel
+	case logmgr.PanicLevel:
+		return zerolog.PanicLevel
+	case logmgr.FatalLevel:
+		return zerolog.FatalLevel
+	default:
+		return zerolog.InfoLevel
+	}
+}
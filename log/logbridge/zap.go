@@ -0,0 +1,314 @@
+package logbridge
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/52debug/go-box/log/logmgr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ZapDebugField 标记 zap logger 已为当前请求开启 debug 日志(见 logmgr.WithDebug)，编码时忽略
+var ZapDebugField = zapcore.Field{Key: "logmgr.debug", Type: zapcore.SkipType}
+
+// HasZapDebugField 返回字段中是否包含 ZapDebugField
+func HasZapDebugField(fields []zapcore.Field) bool {
+	for _, f := range fields {
+		if f.Type == zapcore.SkipType && f.Key == ZapDebugField.Key {
+			return true
+		}
+	}
+	return false
+}
+
+// slogCore 基于 slog 处理器的 zap Core
+type slogCore struct {
+	h   slog.Handler
+	ctx context.Context // 传给处理器的 context，携带 ZapDebugField 时开启 debug
+}
+
+// ZapCoreFromSlog 返回将日志写入 slog 处理器的 zap Core
+func ZapCoreFromSlog(h slog.Handler) zapcore.Core {
+	return &slogCore{h: h, ctx: context.Background()}
+}
+
+func (c *slogCore) Enabled(l zapcore.Level) bool {
+	return c.h.Enabled(c.ctx, zapToSlog(l))
+}
+
+func (c *slogCore) With(fields []zapcore.Field) zapcore.Core {
+	h := c.h
+	// 命名空间之后的字段属于该分组
+	start := 0
+	for i, f := range fields {
+		if f.Type == zapcore.NamespaceType {
+			if attrs := fieldsToAttrs(fields[start:i]); len(attrs) > 0 {
+				h = h.WithAttrs(attrs)
+			}
+			h = h.WithGroup(f.Key)
+			start = i + 1
+		}
+	}
+	if attrs := fieldsToAttrs(fields[start:]); len(attrs) > 0 {
+		h = h.WithAttrs(attrs)
+	}
+	ctx := c.ctx
+	if HasZapDebugField(fields) {
+		ctx = logmgr.WithDebug(ctx)
+	}
+	return &slogCore{h: h, ctx: ctx}
+}
+
+func (c *slogCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *slogCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	var pc uintptr
+	if ent.Caller.Defined {
+		pc = zapCallerPC(ent.Caller)
+	}
+	r := slog.NewRecord(ent.Time, zapToSlog(ent.Level), ent.Message, pc)
+	if ent.LoggerName != "" {
+		r.AddAttrs(slog.String("logger", ent.LoggerName))
+	}
+	r.AddAttrs(fieldsToAttrs(fields)...)
+	if ent.Stack != "" {
+		r.AddAttrs(slog.String(logmgr.StacktraceKey, ent.Stack))
+	}
+	return c.h.Handle(c.ctx, r)
+}
+
+func (c *slogCore) Sync() error {
+	return nil
+}
+
+// fieldsToAttrs 将 zap 字段转换为 slog 属性，命名空间之后的字段放入分组
+func fieldsToAttrs(fields []zapcore.Field) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for i, f := range fields {
+		if f.Type == zapcore.NamespaceType {
+			rest := fieldsToAttrs(fields[i+1:])
+			return append(attrs, slog.Attr{Key: f.Key, Value: slog.GroupValue(rest...)})
+		}
+		if a, ok := fieldToAttr(f); ok {
+			attrs = append(attrs, a)
+		}
+	}
+	return attrs
+}
+
+// fieldToAttr 转换单个 zap 字段，常见类型直接转换，其他类型通过 MapObjectEncoder 编码
+func fieldToAttr(f zapcore.Field) (slog.Attr, bool) {
+	switch f.Type {
+	case zapcore.SkipType:
+		return slog.Attr{}, false
+	case zapcore.StringType:
+		return slog.String(f.Key, f.String), true
+	case zapcore.Int64Type, zapcore.Int32Type, zapcore.Int16Type, zapcore.Int8Type:
+		return slog.Int64(f.Key, f.Integer), true
+	case zapcore.Uint64Type, zapcore.Uint32Type, zapcore.Uint16Type, zapcore.Uint8Type, zapcore.UintptrType:
+		return slog.Uint64(f.Key, uint64(f.Integer)), true
+	case zapcore.Float64Type:
+		return slog.Float64(f.Key, math.Float64frombits(uint64(f.Integer))), true
+	case zapcore.Float32Type:
+		return slog.Float64(f.Key, float64(math.Float32frombits(uint32(f.Integer)))), true
+	case zapcore.BoolType:
+		return slog.Bool(f.Key, f.Integer == 1), true
+	case zapcore.DurationType:
+		return slog.Duration(f.Key, time.Duration(f.Integer)), true
+	case zapcore.TimeType:
+		t := time.Unix(0, f.Integer)
+		if loc, ok := f.Interface.(*time.Location); ok {
+			t = t.In(loc)
+		}
+		return slog.Time(f.Key, t), true
+	case zapcore.TimeFullType:
+		if t, ok := f.Interface.(time.Time); ok {
+			return slog.Time(f.Key, t), true
+		}
+	case zapcore.ErrorType:
+		if err, ok := f.Interface.(error); ok {
+			return slog.Any(f.Key, err), true
+		}
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+	if len(enc.Fields) == 1 {
+		for k, v := range enc.Fields {
+			return valueToAttr(k, v), true
+		}
+	}
+	// 内联对象等会产生多个键
+	return slog.Attr{Key: "", Value: slog.GroupValue(mapToAttrs(enc.Fields)...)}, len(enc.Fields) > 0
+}
+
+// valueToAttr 将 MapObjectEncoder 输出的值转换为属性，嵌套对象转换为分组
+func valueToAttr(key string, v interface{}) slog.Attr {
+	if m, ok := v.(map[string]interface{}); ok {
+		return slog.Attr{Key: key, Value: slog.GroupValue(mapToAttrs(m)...)}
+	}
+	return slog.Any(key, v)
+}
+
+// mapToAttrs 按键名排序转换
+func mapToAttrs(m map[string]interface{}) []slog.Attr {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	attrs := make([]slog.Attr, 0, len(keys))
+	for _, k := range keys {
+		attrs = append(attrs, valueToAttr(k, m[k]))
+	}
+	return attrs
+}
+
+// zapHandler 基于 zap Core 的 slog 处理器
+type zapHandler struct {
+	core  zapcore.Core
+	stack zapcore.LevelEnabler // 非 nil 时为达到该级别的记录附加调用栈
+	debug *debugCore           // 带有 ZapDebugField 的 Core，首次需要时创建
+}
+
+// debugCore 延迟创建的 debug Core，同一处理器的所有调用共享
+type debugCore struct {
+	once sync.Once
+	core zapcore.Core
+}
+
+// SlogHandlerFromZap 返回将日志写入 zap Core 的 slog 处理器
+func SlogHandlerFromZap(core zapcore.Core) slog.Handler {
+	return newZapHandler(core, nil)
+}
+
+// SlogHandlerFromZapStacktrace 与 SlogHandlerFromZap 相同，并为达到 stack 级别的记录附加调用栈，
+// 与 zap.AddStacktrace 对 zap logger 的作用一致；context 中携带调用栈时优先使用
+func SlogHandlerFromZapStacktrace(core zapcore.Core, stack zapcore.LevelEnabler) slog.Handler {
+	return newZapHandler(core, stack)
+}
+
+func newZapHandler(core zapcore.Core, stack zapcore.LevelEnabler) *zapHandler {
+	return &zapHandler{core: core, stack: stack, debug: &debugCore{}}
+}
+
+func (h *zapHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.coreFor(ctx, slogToZap(level)).Enabled(slogToZap(level))
+}
+
+// coreFor 在 context 开启 debug 时返回带有 ZapDebugField 的 Core
+func (h *zapHandler) coreFor(ctx context.Context, level zapcore.Level) zapcore.Core {
+	if level < zapcore.InfoLevel && logmgr.DebugEnabled(ctx) {
+		h.debug.once.Do(func() {
+			h.debug.core = h.core.With([]zapcore.Field{ZapDebugField})
+		})
+		return h.debug.core
+	}
+	return h.core
+}
+
+func (h *zapHandler) Handle(ctx context.Context, r slog.Record) error {
+	ent := zapcore.Entry{
+		Level:   slogToZap(r.Level),
+		Time:    r.Time,
+		Message: r.Message,
+	}
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		ent.Caller = zapcore.EntryCaller{
+			Defined:  true,
+			PC:       r.PC,
+			File:     frame.File,
+			Line:     frame.Line,
+			Function: frame.Function,
+		}
+	}
+	// 直接调用 Core，panic/fatal 级别不会触发 panic 或退出
+	ce := h.coreFor(ctx, ent.Level).Check(ent, nil)
+	if ce == nil {
+		return nil
+	}
+	if stack, ok := logmgr.StacktraceFromContext(ctx); ok {
+		ce.Entry.Stack = stack
+	} else if h.stack != nil && h.stack.Enabled(ent.Level) {
+		ce.Entry.Stack = logmgr.Stacktrace(0, stackSkipPrefixes...)
+	}
+	fields := make([]zapcore.Field, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendField(fields, a)
+		return true
+	})
+	ce.Write(fields...)
+	return nil
+}
+
+func (h *zapHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var fields []zapcore.Field
+	for _, a := range attrs {
+		fields = appendField(fields, a)
+	}
+	return newZapHandler(h.core.With(fields), h.stack)
+}
+
+func (h *zapHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return newZapHandler(h.core.With([]zapcore.Field{zap.Namespace(name)}), h.stack)
+}
+
+// appendField 将 slog 属性转换为 zap 字段
+func appendField(fields []zapcore.Field, a slog.Attr) []zapcore.Field {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return append(fields, zap.String(a.Key, v.String()))
+	case slog.KindInt64:
+		return append(fields, zap.Int64(a.Key, v.Int64()))
+	case slog.KindUint64:
+		return append(fields, zap.Uint64(a.Key, v.Uint64()))
+	case slog.KindFloat64:
+		return append(fields, zap.Float64(a.Key, v.Float64()))
+	case slog.KindBool:
+		return append(fields, zap.Bool(a.Key, v.Bool()))
+	case slog.KindDuration:
+		return append(fields, zap.Duration(a.Key, v.Duration()))
+	case slog.KindTime:
+		return append(fields, zap.Time(a.Key, v.Time()))
+	case slog.KindGroup:
+		attrs := v.Group()
+		if len(attrs) == 0 {
+			return fields
+		}
+		group := zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			var sub []zapcore.Field
+			for _, ga := range attrs {
+				sub = appendField(sub, ga)
+			}
+			for _, f := range sub {
+				f.AddTo(enc)
+			}
+			return nil
+		})
+		if a.Key == "" {
+			return append(fields, zap.Inline(group))
+		}
+		return append(fields, zap.Object(a.Key, group))
+	default:
+		if a.Key == "" {
+			return fields
+		}
+		return append(fields, zap.Any(a.Key, v.Any()))
+	}
+}
@@ -0,0 +1,46 @@
+package logbridge
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/52debug/go-box/log/logmgr"
+	"go.uber.org/zap/zapcore"
+)
+
+// withCounter 统计 With 的调用次数
+type withCounter struct {
+	zapcore.Core
+	calls *int
+}
+
+func (c withCounter) With(fields []zapcore.Field) zapcore.Core {
+	*c.calls++
+	return withCounter{Core: c.Core.With(fields), calls: c.calls}
+}
+
+func TestZapHandlerDebugCoreCached(t *testing.T) {
+	var calls int
+	core := withCounter{Core: zapcore.NewNopCore(), calls: &calls}
+	logger := slog.New(SlogHandlerFromZap(core))
+
+	ctx := logmgr.WithDebug(context.Background())
+	for i := 0; i < 10; i++ {
+		logger.DebugContext(ctx, "debug")
+		logger.InfoContext(ctx, "info")
+	}
+	if calls != 1 {
+		t.Fatalf("With 调用 %d 次，期望只创建一次 debug Core", calls)
+	}
+
+	// With 返回的新处理器单独缓存
+	child := logger.With("k", "v")
+	calls = 0
+	for i := 0; i < 10; i++ {
+		child.DebugContext(ctx, "debug")
+	}
+	if calls != 1 {
+		t.Fatalf("子处理器 With 调用 %d 次", calls)
+	}
+}
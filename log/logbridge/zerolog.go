@@ -0,0 +1,379 @@
+package logbridge
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"time"
+
+	"github.com/52debug/go-box/log/logmgr"
+	"github.com/rs/zerolog"
+)
+
+// 查找调用位置时跳过的日志库内部栈帧
+var zerologSkipPrefixes = []string{
+	"github.com/52debug/go-box/log/logbridge.",
+	"github.com/rs/zerolog.",
+	"github.com/rs/zerolog/log.",
+}
+
+// zerologDebugKey slogHook 为 context 开启 debug 的事件添加的标记字段，slogWriter 据此开启 debug 并移除该字段。
+// 字段名带有进程内随机生成的后缀，日志内容无法伪造
+var zerologDebugKey = "_logmgr_debug_" + randomSuffix()
+
+func randomSuffix() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// slogWriter 解析 zerolog 输出的 JSON 并交给 slog 处理器
+type slogWriter struct {
+	h slog.Handler
+}
+
+// ZerologWriterFromSlog 返回将 zerolog 事件写入 slog 处理器的 writer，
+// 使用方式: zerolog.New(ZerologWriterFromSlog(h))。
+// writer 看不到事件的 context，按 context 开启的 debug 日志需要使用 ZerologFromSlog
+func ZerologWriterFromSlog(h slog.Handler) zerolog.LevelWriter {
+	return &slogWriter{h: h}
+}
+
+// ZerologFromSlog 返回写入 slog 处理器的 zerolog.Logger，
+// 由 hook 根据事件的 context 判断处理器是否输出该级别
+func ZerologFromSlog(h slog.Handler) zerolog.Logger {
+	return zerolog.New(&slogWriter{h: h}).Hook(slogHook{h: h})
+}
+
+// slogHook 丢弃 slog 处理器在事件的 context 下不输出的事件，
+// 事件的 context 开启了 debug 时添加标记字段，由 writer 传给处理器
+type slogHook struct {
+	h slog.Handler
+}
+
+func (k slogHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	if level == zerolog.NoLevel {
+		return
+	}
+	ctx := e.GetCtx()
+	if !k.h.Enabled(ctx, zerologToSlog(level)) {
+		e.Discard()
+		return
+	}
+	if logmgr.DebugEnabled(ctx) {
+		e.Bool(zerologDebugKey, true)
+	}
+}
+
+func (w *slogWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+func (w *slogWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	evt, err := decodeAttrs(p)
+	if err != nil {
+		return 0, fmt.Errorf("无法解析日志事件: %w", err)
+	}
+
+	// 取出内置字段，其余字段保持原有顺序
+	var (
+		msg   string
+		ts    time.Time
+		debug bool
+	)
+	attrs := evt[:0]
+	for _, a := range evt {
+		switch a.Key {
+		case zerolog.MessageFieldName:
+			msg = a.Value.String()
+		case zerolog.LevelFieldName:
+			if level == zerolog.NoLevel {
+				if l, err := zerolog.ParseLevel(a.Value.String()); err == nil {
+					level = l
+				}
+			}
+		case zerolog.TimestampFieldName:
+			ts, _ = zerologTime(a.Value)
+		case zerolog.CallerFieldName:
+		case zerologDebugKey:
+			debug = true
+		default:
+			attrs = append(attrs, a)
+		}
+	}
+
+	slogLevel := zerologToSlog(level)
+	ctx := context.Background()
+	if debug {
+		// slogHook 已按事件的 context 判断过级别
+		ctx = logmgr.WithDebug(ctx)
+	} else if !w.h.Enabled(ctx, slogLevel) {
+		return len(p), nil
+	}
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	r := slog.NewRecord(ts, slogLevel, msg, callerPC(zerologSkipPrefixes))
+	r.AddAttrs(attrs...)
+	if err := w.h.Handle(ctx, r); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// zerologTime 按 zerolog.TimeFieldFormat 解析时间字段
+func zerologTime(v slog.Value) (time.Time, bool) {
+	var unit time.Duration
+	switch zerolog.TimeFieldFormat {
+	case zerolog.TimeFormatUnix:
+		unit = time.Second
+	case zerolog.TimeFormatUnixMs:
+		unit = time.Millisecond
+	case zerolog.TimeFormatUnixMicro:
+		unit = time.Microsecond
+	case zerolog.TimeFormatUnixNano:
+		unit = time.Nanosecond
+	default:
+		if v.Kind() != slog.KindString {
+			return time.Time{}, false
+		}
+		t, err := time.Parse(zerolog.TimeFieldFormat, v.String())
+		return t, err == nil
+	}
+	switch v.Kind() {
+	case slog.KindInt64:
+		return time.Unix(0, v.Int64()*int64(unit)), true
+	case slog.KindFloat64:
+		return time.Unix(0, int64(v.Float64()*float64(unit))), true
+	}
+	return time.Time{}, false
+}
+
+// decodeAttrs 按字段出现的顺序将 JSON 对象转换为属性，嵌套对象转换为分组
+func decodeAttrs(data []byte) ([]slog.Attr, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	tok, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok != json.Delim('{') {
+		return nil, fmt.Errorf("期望 JSON 对象，得到 %v", tok)
+	}
+	var attrs []slog.Attr
+	for d.More() {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+		var raw json.RawMessage
+		if err := d.Decode(&raw); err != nil {
+			return nil, err
+		}
+		if len(raw) > 0 && raw[0] == '{' {
+			group, err := decodeAttrs(raw)
+			if err != nil {
+				return nil, err
+			}
+			attrs = append(attrs, slog.Attr{Key: key, Value: slog.GroupValue(group...)})
+			continue
+		}
+		var v interface{}
+		vd := json.NewDecoder(bytes.NewReader(raw))
+		vd.UseNumber()
+		if err := vd.Decode(&v); err != nil {
+			return nil, err
+		}
+		attrs = append(attrs, jsonAttr(key, v))
+	}
+	return attrs, nil
+}
+
+// jsonAttr 将 JSON 值转换为属性，数字优先转换为整数
+func jsonAttr(key string, v interface{}) slog.Attr {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return slog.Int64(key, i)
+		} else if f, err := v.Float64(); err == nil {
+			return slog.Float64(key, f)
+		}
+		return slog.String(key, v.String())
+	case string:
+		return slog.String(key, v)
+	case bool:
+		return slog.Bool(key, v)
+	default:
+		return slog.Any(key, v)
+	}
+}
+
+// zerologHandler 基于 zerolog.Logger 的 slog 处理器
+type zerologHandler struct {
+	logger zerolog.Logger
+	debug  *zerolog.Logger // context 开启 debug 时使用的 logger，可以为 nil
+	caller bool            // 是否根据记录的 PC 添加调用位置
+	groups []string        // 当前打开的分组
+	scoped []scopedFields  // 分组内通过 WithAttrs 添加的属性
+}
+
+// scopedFields 属于某个分组路径的属性
+type scopedFields struct {
+	depth int
+	attrs []slog.Attr
+}
+
+// SlogHandlerFromZerolog 返回将日志写入 zerolog.Logger 的 slog 处理器，
+// caller 为 true 时由处理器根据记录的 PC 添加调用位置，此时 logger 本身不应再开启 Caller
+func SlogHandlerFromZerolog(logger zerolog.Logger, caller bool) slog.Handler {
+	return &zerologHandler{logger: logger, caller: caller}
+}
+
+// SlogHandlerFromZerologDebug 与 SlogHandlerFromZerolog 相同，
+// context 通过 logmgr.WithDebug 开启 debug 时改为写入 debug
+func SlogHandlerFromZerologDebug(logger, debug zerolog.Logger, caller bool) slog.Handler {
+	return &zerologHandler{logger: logger, debug: &debug, caller: caller}
+}
+
+// loggerFor 返回 ctx 使用的 logger
+func (h *zerologHandler) loggerFor(ctx context.Context) *zerolog.Logger {
+	if h.debug != nil && logmgr.DebugEnabled(ctx) {
+		return h.debug
+	}
+	return &h.logger
+}
+
+func (h *zerologHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	l := slogToZerolog(level)
+	return l >= h.loggerFor(ctx).GetLevel() && l >= zerolog.GlobalLevel()
+}
+
+func (h *zerologHandler) Handle(ctx context.Context, r slog.Record) error {
+	// WithLevel 在 panic/fatal 级别不会触发 panic 或退出
+	e := h.loggerFor(ctx).WithLevel(slogToZerolog(r.Level))
+	if e == nil {
+		return nil
+	}
+	e = e.Ctx(ctx)
+	if h.caller && r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		e = e.Str(zerolog.CallerFieldName, zerolog.CallerMarshalFunc(r.PC, frame.File, frame.Line))
+	}
+
+	var attrs []slog.Attr
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	if len(h.groups) > 0 {
+		e = e.Fields(h.groupedFields(attrs))
+	} else if len(attrs) > 0 {
+		e = e.Fields(attrsToFields(attrs))
+	}
+	e.Msg(r.Message)
+	return nil
+}
+
+func (h *zerologHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	if len(h.groups) == 0 {
+		fields := attrsToFields(attrs)
+		h2.logger = h.logger.With().Fields(fields).Logger()
+		if h.debug != nil {
+			debug := h.debug.With().Fields(fields).Logger()
+			h2.debug = &debug
+		}
+		return &h2
+	}
+	h2.scoped = append(h.scoped[:len(h.scoped):len(h.scoped)], scopedFields{depth: len(h.groups), attrs: attrs})
+	return &h2
+}
+
+func (h *zerologHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(h.groups[:len(h.groups):len(h.groups)], name)
+	return &h2
+}
+
+// groupedFields 将分组内的属性与记录属性组装为嵌套对象
+func (h *zerologHandler) groupedFields(attrs []slog.Attr) []interface{} {
+	root := map[string]interface{}{}
+	for _, s := range h.scoped {
+		insertAttrs(root, h.groups[:s.depth], s.attrs)
+	}
+	insertAttrs(root, h.groups, attrs)
+	top := h.groups[0]
+	return []interface{}{top, root[top]}
+}
+
+// insertAttrs 将属性写入 path 指定的嵌套对象
+func insertAttrs(root map[string]interface{}, path []string, attrs []slog.Attr) {
+	m := root
+	for _, g := range path {
+		sub, ok := m[g].(map[string]interface{})
+		if !ok {
+			sub = map[string]interface{}{}
+			m[g] = sub
+		}
+		m = sub
+	}
+	for _, a := range attrs {
+		a.Value = a.Value.Resolve()
+		if a.Value.Kind() == slog.KindGroup && a.Key == "" {
+			insertAttrs(root, path, a.Value.Group())
+			continue
+		}
+		if a.Key != "" {
+			m[a.Key] = attrValue(a.Value)
+		}
+	}
+}
+
+// attrsToFields 将属性转换为 zerolog 的 []interface{} 字段列表，保持顺序
+func attrsToFields(attrs []slog.Attr) []interface{} {
+	fields := make([]interface{}, 0, len(attrs)*2)
+	for _, a := range attrs {
+		a.Value = a.Value.Resolve()
+		if a.Value.Kind() == slog.KindGroup && a.Key == "" {
+			fields = append(fields, attrsToFields(a.Value.Group())...)
+			continue
+		}
+		if a.Key == "" {
+			continue
+		}
+		fields = append(fields, a.Key, attrValue(a.Value))
+	}
+	return fields
+}
+
+// attrValue 将属性值转换为 zerolog 可以编码的 Go 值，分组转换为 map
+func attrValue(v slog.Value) interface{} {
+	switch v.Kind() {
+	case slog.KindGroup:
+		m := make(map[string]interface{})
+		for _, a := range v.Group() {
+			a.Value = a.Value.Resolve()
+			if a.Key != "" {
+				m[a.Key] = attrValue(a.Value)
+			}
+		}
+		return m
+	case slog.KindLogValuer:
+		return attrValue(v.Resolve())
+	default:
+		return v.Any()
+	}
+}
@@ -0,0 +1,151 @@
+package logbridge
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/52debug/go-box/log/logmgr"
+	"github.com/rs/zerolog"
+)
+
+// recordHandler 保存收到的记录
+type recordHandler struct {
+	records []slog.Record
+}
+
+func (h *recordHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordHandler) Handle(_ context.Context, r slog.Record) error {
+	h.records = append(h.records, r)
+	return nil
+}
+
+func (h *recordHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *recordHandler) WithGroup(string) slog.Handler      { return h }
+
+// attrString 按顺序输出记录的属性，分组展开为 key.sub
+func attrString(r slog.Record) string {
+	var parts []string
+	var walk func(prefix string, a slog.Attr)
+	walk = func(prefix string, a slog.Attr) {
+		if a.Value.Kind() == slog.KindGroup {
+			for _, ga := range a.Value.Group() {
+				walk(prefix+a.Key+".", ga)
+			}
+			return
+		}
+		parts = append(parts, prefix+a.Key+"="+a.Value.String())
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		walk("", a)
+		return true
+	})
+	return strings.Join(parts, " ")
+}
+
+func TestZerologWriterFieldOrder(t *testing.T) {
+	h := &recordHandler{}
+	logger := zerolog.New(ZerologWriterFromSlog(h))
+	logger.Info().
+		Str("zeta", "z").
+		Int("alpha", 1).
+		Dict("req", zerolog.Dict().Str("path", "/a").Int("code", 200)).
+		Bool("mid", true).
+		Float64("ratio", 0.5).
+		Msg("hello")
+
+	if len(h.records) != 1 {
+		t.Fatalf("收到 %d 条记录", len(h.records))
+	}
+	r := h.records[0]
+	if r.Message != "hello" || r.Level != slog.LevelInfo {
+		t.Fatalf("消息 %q，级别 %v", r.Message, r.Level)
+	}
+	if got, want := attrString(r), "zeta=z alpha=1 req.path=/a req.code=200 mid=true ratio=0.5"; got != want {
+		t.Fatalf("属性 %q，期望 %q", got, want)
+	}
+}
+
+func TestZerologWriterTimestamp(t *testing.T) {
+	ts := time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC)
+	tests := []struct {
+		format string
+		want   time.Time
+	}{
+		{format: time.RFC3339, want: ts.Truncate(time.Second)},
+		{format: time.RFC3339Nano, want: ts},
+		{format: "2006-01-02 15:04:05.000", want: ts.Truncate(time.Millisecond)},
+		{format: zerolog.TimeFormatUnix, want: ts.Truncate(time.Second)},
+		{format: zerolog.TimeFormatUnixMs, want: ts.Truncate(time.Millisecond)},
+		{format: zerolog.TimeFormatUnixMicro, want: ts.Truncate(time.Microsecond)},
+		{format: zerolog.TimeFormatUnixNano, want: ts},
+	}
+	saved := zerolog.TimeFieldFormat
+	defer func() { zerolog.TimeFieldFormat = saved }()
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			zerolog.TimeFieldFormat = tt.format
+			h := &recordHandler{}
+			logger := zerolog.New(ZerologWriterFromSlog(h))
+			logger.Info().Time(zerolog.TimestampFieldName, ts).Msg("m")
+			logger.Info().Msg("no time")
+
+			if len(h.records) != 2 {
+				t.Fatalf("收到 %d 条记录", len(h.records))
+			}
+			if got := h.records[0].Time; !got.Equal(tt.want) {
+				t.Fatalf("时间 %v，期望 %v", got, tt.want)
+			}
+			if got := h.records[0].NumAttrs(); got != 0 {
+				t.Fatalf("时间字段未从属性中移除: %s", attrString(h.records[0]))
+			}
+			// 没有时间字段时使用当前时间
+			if got := h.records[1].Time; time.Since(got) > time.Minute {
+				t.Fatalf("缺少时间字段时的时间 %v", got)
+			}
+		})
+	}
+}
+
+// debugLevelHandler 输出 info 及以上的记录，context 开启 debug 时输出 debug 记录
+type debugLevelHandler struct {
+	recordHandler
+}
+
+func (h *debugLevelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= slog.LevelInfo || (level >= slog.LevelDebug && logmgr.DebugEnabled(ctx))
+}
+
+func TestZerologFromSlogContextDebug(t *testing.T) {
+	h := &debugLevelHandler{}
+	logger := ZerologFromSlog(h)
+	ctx := logmgr.WithDebug(context.Background())
+	field := `,"_logmgr_debug":true`
+
+	logger.Debug().Ctx(ctx).Str("raw", field).Msg("debug")
+	logger.Debug().Msg("skip")
+	logger.Trace().Ctx(ctx).Msg("skip")
+	logger.Info().Str("raw", field).Msg(field)
+
+	if len(h.records) != 2 {
+		t.Fatalf("收到 %d 条记录", len(h.records))
+	}
+	if r := h.records[0]; r.Message != "debug" || r.Level != slog.LevelDebug || attrString(r) != "raw="+field {
+		t.Fatalf("debug 记录 %q %v %s", r.Message, r.Level, attrString(r))
+	}
+	if r := h.records[1]; r.Message != field || attrString(r) != "raw="+field {
+		t.Fatalf("info 记录 %q %s", r.Message, attrString(r))
+	}
+
+	// 单独使用 writer 时看不到事件的 context
+	h = &debugLevelHandler{}
+	logger = zerolog.New(ZerologWriterFromSlog(h))
+	logger.Debug().Ctx(ctx).Msg("skip")
+	if len(h.records) != 0 {
+		t.Fatalf("收到 %d 条记录", len(h.records))
+	}
+}
@@ -0,0 +1,280 @@
+// Package audit 提供防篡改的审计日志
+//
+// 每条记录为一行 JSON，包含递增的序号 seq 和前一条记录(整行)的 SHA-256 哈希 prev，
+// 配置密钥时附加 HMAC-SHA256。删除或修改任意一行都会使之后的哈希链断裂，可通过 Verify 检查。
+package audit
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/52debug/go-box/log/logmgr"
+)
+
+// record 审计记录，字段顺序即输出顺序
+type record struct {
+	Seq   uint64                 `json:"seq"`
+	Time  string                 `json:"time"`
+	Level string                 `json:"level"`
+	Msg   string                 `json:"msg"`
+	Attrs map[string]interface{} `json:"attrs,omitempty"`
+	Prev  string                 `json:"prev"`
+	HMAC  string                 `json:"hmac,omitempty"`
+}
+
+// Sink 审计日志输出，可并发使用
+type Sink struct {
+	mu   sync.Mutex
+	out  io.WriteCloser
+	key  []byte
+	seq  uint64 // 最后一条记录的序号
+	prev string // 最后一条记录的哈希
+}
+
+// Open 打开审计日志，使用 config 中的 FilePath、轮转、权限和压缩设置，key 为空时不计算 HMAC
+//
+// 已有日志时从最后一条记录继续哈希链，轮转后的新文件同样延续哈希链。
+// 哈希链只能由一个进程写入，不支持 MultiProcess。
+func Open(config logmgr.LogConfig, key []byte) (*Sink, error) {
+	if config.MultiProcess {
+		return nil, errors.New("审计日志不支持多进程写入: 各进程会从各自的最后一条记录继续，哈希链会分叉")
+	}
+	out, err := config.NewRotatingFile()
+	if err != nil {
+		return nil, err
+	}
+	s := &Sink{out: out, key: key}
+	if err := s.resume(config.FilePath); err != nil {
+		out.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// resume 从最新的日志文件中读取最后一条记录
+func (s *Sink) resume(path string) error {
+	files, err := logmgr.LogFiles(path)
+	if err != nil {
+		return err
+	}
+	for i := len(files) - 1; i >= 0; i-- {
+		line, err := lastLine(files[i])
+		if err != nil {
+			return err
+		}
+		if line == nil {
+			continue
+		}
+		var rec record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return fmt.Errorf("无法解析审计日志 %s 的最后一条记录: %w", files[i], err)
+		}
+		s.seq = rec.Seq
+		s.prev = hashLine(line)
+		return nil
+	}
+	return nil
+}
+
+// lastLine 返回文件中最后一个非空行，文件为空时返回 nil
+func lastLine(path string) ([]byte, error) {
+	f, err := logmgr.OpenLogFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var last []byte
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if line = trimNewline(line); len(line) > 0 {
+			last = line
+		}
+		if err == io.EOF {
+			return last, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Log 写入一条审计记录
+func (s *Sink) Log(level slog.Level, msg string, attrs ...slog.Attr) error {
+	return s.write(time.Now(), level, msg, attrsToMap(nil, nil, attrs))
+}
+
+func (s *Sink) write(t time.Time, level slog.Level, msg string, attrs map[string]interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec := record{
+		Seq:   s.seq + 1,
+		Time:  t.Format(time.RFC3339Nano),
+		Level: logmgr.SlogLevelName(level),
+		Msg:   msg,
+		Attrs: attrs,
+		Prev:  s.prev,
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if s.key != nil {
+		// HMAC 覆盖除 hmac 字段外的整条记录
+		mac := computeHMAC(s.key, line)
+		line = append(line[:len(line)-1], `,"hmac":"`...)
+		line = append(line, mac...)
+		line = append(line, `"}`...)
+	}
+	if _, err := s.out.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	s.seq = rec.Seq
+	s.prev = hashLine(line)
+	return nil
+}
+
+// Close 关闭审计日志文件
+func (s *Sink) Close() error {
+	return s.out.Close()
+}
+
+// Handler 返回写入审计日志的 slog 处理器，属性记录在 attrs 字段中
+func (s *Sink) Handler() slog.Handler {
+	return &handler{sink: s}
+}
+
+// handler 基于 Sink 的 slog 处理器
+type handler struct {
+	sink   *Sink
+	groups []string
+	scoped []scopedAttrs
+}
+
+type scopedAttrs struct {
+	groups []string
+	attrs  []slog.Attr
+}
+
+func (h *handler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h *handler) Handle(_ context.Context, r slog.Record) error {
+	var m map[string]interface{}
+	for _, sc := range h.scoped {
+		m = attrsToMap(m, sc.groups, sc.attrs)
+	}
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	m = attrsToMap(m, h.groups, attrs)
+	return h.sink.write(r.Time, r.Level, r.Message, m)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.scoped = append(h.scoped[:len(h.scoped):len(h.scoped)], scopedAttrs{groups: h.groups, attrs: attrs})
+	return &h2
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(h.groups[:len(h.groups):len(h.groups)], name)
+	return &h2
+}
+
+// attrsToMap 将属性写入 m 中 groups 指定的嵌套对象，m 为 nil 时创建
+func attrsToMap(m map[string]interface{}, groups []string, attrs []slog.Attr) map[string]interface{} {
+	if len(attrs) == 0 {
+		return m
+	}
+	if m == nil {
+		m = map[string]interface{}{}
+	}
+	target := m
+	for _, g := range groups {
+		sub, ok := target[g].(map[string]interface{})
+		if !ok {
+			sub = map[string]interface{}{}
+			target[g] = sub
+		}
+		target = sub
+	}
+	for _, a := range attrs {
+		a.Value = a.Value.Resolve()
+		if a.Value.Kind() == slog.KindGroup {
+			if a.Key == "" {
+				attrsToMap(m, groups, a.Value.Group())
+			} else {
+				attrsToMap(m, append(groups[:len(groups):len(groups)], a.Key), a.Value.Group())
+			}
+			continue
+		}
+		if a.Key != "" {
+			target[a.Key] = attrValue(a.Value)
+		}
+	}
+	return m
+}
+
+// attrValue 将属性值转换为 JSON 可编码的值，无法编码的值转换为字符串
+func attrValue(v slog.Value) interface{} {
+	switch v.Kind() {
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339Nano)
+	case slog.KindDuration:
+		return v.Duration().String()
+	case slog.KindFloat64:
+		if f := v.Float64(); math.IsNaN(f) || math.IsInf(f, 0) {
+			return v.String()
+		}
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return err.Error()
+		}
+		if _, err := json.Marshal(v.Any()); err != nil {
+			return fmt.Sprintf("%+v", v.Any())
+		}
+	}
+	return v.Any()
+}
+
+// hashLine 返回记录(不含换行)的 SHA-256
+func hashLine(line []byte) string {
+	sum := sha256.Sum256(line)
+	return hex.EncodeToString(sum[:])
+}
+
+func computeHMAC(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	dst := make([]byte, hex.EncodedLen(len(sum)))
+	hex.Encode(dst, sum)
+	return dst
+}
+
+func trimNewline(line []byte) []byte {
+	for len(line) > 0 && (line[len(line)-1] == '\n' || line[len(line)-1] == '\r') {
+		line = line[:len(line)-1]
+	}
+	return line
+}
@@ -0,0 +1,159 @@
+package audit
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/52debug/go-box/log/logmgr"
+)
+
+func TestOpenMultiProcess(t *testing.T) {
+	_, err := Open(logmgr.LogConfig{FilePath: filepath.Join(t.TempDir(), "audit.log"), MultiProcess: true}, nil)
+	if err == nil || !strings.Contains(err.Error(), "多进程") {
+		t.Fatalf("期望拒绝 MultiProcess，得到 %v", err)
+	}
+}
+
+func TestLogUnmarshalableAttr(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	s, err := Open(logmgr.LogConfig{FilePath: path}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	err = s.Log(slog.LevelInfo, "m",
+		slog.Any("ch", make(chan int)),
+		slog.Float64("nan", math.NaN()),
+		slog.Any("fn", func() {}),
+		slog.String("ok", "v"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"ch":"0x`, `"nan":"NaN"`, `"fn":"0x`, `"ok":"v"`} {
+		if !bytes.Contains(data, []byte(want)) {
+			t.Fatalf("记录 %s 中缺少 %s", data, want)
+		}
+	}
+}
+
+func TestVerify(t *testing.T) {
+	key := []byte("secret")
+	tests := []struct {
+		name     string
+		key      []byte
+		edit     func(lines []string) []string
+		seq      uint64 // 断裂处的序号，0 表示校验通过
+		reason   string
+		anchored bool
+	}{
+		{name: "intact", edit: func(l []string) []string { return l }},
+		{name: "intact hmac", key: key, edit: func(l []string) []string { return l }},
+		{
+			name:   "tampered",
+			edit:   func(l []string) []string { l[2] = strings.Replace(l[2], `"msg":"m3"`, `"msg":"mX"`, 1); return l },
+			seq:    4,
+			reason: "哈希不匹配",
+		},
+		{
+			// 修改最后一条记录无法通过哈希链发现，由 HMAC 发现
+			name: "tampered hmac",
+			key:  key,
+			edit: func(l []string) []string {
+				l[4] = strings.Replace(l[4], `"msg":"m5"`, `"msg":"mX"`, 1)
+				return l
+			},
+			seq:    5,
+			reason: "HMAC 不匹配",
+		},
+		{
+			name:   "deleted",
+			edit:   func(l []string) []string { return append(l[:1], l[2:]...) },
+			seq:    3,
+			reason: "序号不连续",
+		},
+		{
+			name:   "reordered",
+			edit:   func(l []string) []string { l[1], l[2] = l[2], l[1]; return l },
+			seq:    3,
+			reason: "序号不连续",
+		},
+		{
+			// 开头被截断时无法发现，只能标记为 Anchored
+			name:     "head truncated",
+			edit:     func(l []string) []string { return l[2:] },
+			anchored: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "audit.log")
+			s, err := Open(logmgr.LogConfig{FilePath: path}, tt.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, msg := range []string{"m1", "m2", "m3", "m4", "m5"} {
+				if err := s.Log(slog.LevelInfo, msg, slog.String("user", "bob")); err != nil {
+					t.Fatal(err)
+				}
+			}
+			s.Close()
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := tt.edit(strings.Split(strings.TrimSuffix(string(data), "\n"), "\n"))
+			if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := Verify(path, tt.key)
+			if tt.seq == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if res.Records != len(lines) || res.Anchored != tt.anchored {
+					t.Fatalf("结果 %+v", res)
+				}
+				return
+			}
+			var broken *BrokenLinkError
+			if !errors.As(err, &broken) {
+				t.Fatalf("期望 BrokenLinkError，得到 %v", err)
+			}
+			if broken.Seq != tt.seq || !strings.Contains(broken.Reason, tt.reason) {
+				t.Fatalf("断裂位置 %v，期望 seq %d: %s", broken, tt.seq, tt.reason)
+			}
+		})
+	}
+}
+
+func TestResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	for i := 0; i < 2; i++ {
+		s, err := Open(logmgr.LogConfig{FilePath: path}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.Log(slog.LevelInfo, "a")
+		s.Log(slog.LevelWarn, "b")
+		s.Close()
+	}
+	res, err := Verify(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.FirstSeq != 1 || res.LastSeq != 4 || res.Anchored {
+		t.Fatalf("结果 %+v", res)
+	}
+}
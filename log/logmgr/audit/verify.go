@@ -0,0 +1,125 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/52debug/go-box/log/logmgr"
+)
+
+// Result 校验结果
+type Result struct {
+	Files    []string // 按顺序校验的文件
+	Records  int      // 记录条数
+	FirstSeq uint64
+	LastSeq  uint64
+	// Anchored 最早的记录不是第 1 条，其 prev 无法校验。可能是旧文件被轮转清理，
+	// 也可能是最早的文件开头被截断，需要结合 MaxBackups 等配置确认
+	Anchored bool
+}
+
+// BrokenLinkError 哈希链断裂的位置
+type BrokenLinkError struct {
+	File   string
+	Line   int // 文件中的行号，从 1 开始
+	Seq    uint64
+	Reason string
+}
+
+func (e *BrokenLinkError) Error() string {
+	return fmt.Sprintf("%s:%d (seq %d): %s", e.File, e.Line, e.Seq, e.Reason)
+}
+
+// hmacSuffixLen 行尾 `,"hmac":"<64 位十六进制>"}` 的长度
+const hmacSuffixLen = len(`,"hmac":""}`) + 64
+
+// Verify 按时间顺序校验 path 及其备份文件中的审计记录，返回第一处断裂的位置(*BrokenLinkError)
+//
+// key 不为空时同时校验每条记录的 HMAC。末尾记录被整体删除无法通过哈希链发现，需结合 LastSeq 判断。
+func Verify(path string, key []byte) (Result, error) {
+	files, err := logmgr.LogFiles(path)
+	if err != nil {
+		return Result{}, err
+	}
+	res := Result{Files: files}
+	var prevHash string
+	for _, file := range files {
+		f, err := logmgr.OpenLogFile(file)
+		if err != nil {
+			return res, err
+		}
+		err = verifyFile(f, file, key, &res, &prevHash)
+		f.Close()
+		if err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+func verifyFile(r io.Reader, file string, key []byte, res *Result, prevHash *string) error {
+	br := bufio.NewReader(r)
+	for lineNo := 1; ; lineNo++ {
+		line, err := br.ReadBytes('\n')
+		if line = trimNewline(line); len(line) > 0 {
+			if lerr := verifyLine(line, key, res, prevHash); lerr != nil {
+				lerr.File = file
+				lerr.Line = lineNo
+				return lerr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func verifyLine(line, key []byte, res *Result, prevHash *string) *BrokenLinkError {
+	var rec record
+	d := json.NewDecoder(bytes.NewReader(line))
+	d.UseNumber()
+	if err := d.Decode(&rec); err != nil {
+		return &BrokenLinkError{Seq: res.LastSeq + 1, Reason: "无法解析记录: " + err.Error()}
+	}
+
+	if res.Records == 0 {
+		res.FirstSeq = rec.Seq
+		if rec.Seq == 1 {
+			if rec.Prev != "" {
+				return &BrokenLinkError{Seq: rec.Seq, Reason: "第一条记录的 prev 应为空"}
+			}
+		} else {
+			res.Anchored = true
+		}
+	} else {
+		if rec.Seq != res.LastSeq+1 {
+			return &BrokenLinkError{Seq: rec.Seq, Reason: fmt.Sprintf("序号不连续: 期望 %d", res.LastSeq+1)}
+		}
+		if rec.Prev != *prevHash {
+			return &BrokenLinkError{Seq: rec.Seq, Reason: "与前一条记录的哈希不匹配"}
+		}
+	}
+
+	if key != nil {
+		if rec.HMAC == "" || len(line) < hmacSuffixLen ||
+			!bytes.HasPrefix(line[len(line)-hmacSuffixLen:], []byte(`,"hmac":"`)) {
+			return &BrokenLinkError{Seq: rec.Seq, Reason: "缺少 HMAC"}
+		}
+		signed := append(line[:len(line)-hmacSuffixLen:len(line)-hmacSuffixLen], '}')
+		if !hmac.Equal(computeHMAC(key, signed), []byte(rec.HMAC)) {
+			return &BrokenLinkError{Seq: rec.Seq, Reason: "HMAC 不匹配"}
+		}
+	}
+
+	res.Records++
+	res.LastSeq = rec.Seq
+	*prevHash = hashLine(line)
+	return nil
+}
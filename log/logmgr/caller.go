@@ -0,0 +1,106 @@
+package logmgr
+
+import (
+	"context"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// 调用位置格式
+const (
+	CallerShort    = "short"    // 目录/文件:行号
+	CallerFull     = "full"     // 完整路径:行号
+	CallerFunction = "function" // 包.函数:行号
+)
+
+// StacktraceKey 堆栈字段名
+const StacktraceKey = "stacktrace"
+
+// FormatCaller 按格式输出调用位置，未知格式按 short 处理，行号不大于 0 时省略
+func FormatCaller(format, file string, line int, function string) string {
+	return string(appendCaller(nil, format, file, line, function))
+}
+
+// appendCaller 追加 FormatCaller 的结果，没有文件名时输出函数名
+func appendCaller(buf []byte, format, file string, line int, function string) []byte {
+	switch {
+	case function != "" && (format == CallerFunction || file == ""):
+		if idx := strings.LastIndexByte(function, '/'); idx >= 0 {
+			function = function[idx+1:]
+		}
+		buf = append(buf, function...)
+	case format == CallerFull:
+		buf = append(buf, file...)
+	default:
+		buf = append(buf, trimCallerPath(file, 2, false)...)
+	}
+	if line > 0 {
+		buf = append(buf, ':')
+		buf = strconv.AppendInt(buf, int64(line), 10)
+	}
+	return buf
+}
+
+type stacktraceKey struct{}
+
+// ContextWithStacktrace 返回携带调用栈的 context，日志后端记录时使用该调用栈而不是当前调用栈，
+// stack 为空时不记录调用栈
+func ContextWithStacktrace(ctx context.Context, stack string) context.Context {
+	return context.WithValue(ctx, stacktraceKey{}, stack)
+}
+
+// StacktraceFromContext 返回 ContextWithStacktrace 设置的调用栈
+func StacktraceFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	stack, ok := ctx.Value(stacktraceKey{}).(string)
+	return stack, ok
+}
+
+// Stacktrace 返回当前 goroutine 的调用栈，格式与 zap 一致
+//
+// skip 为额外跳过的层数，之后开头连续属于 skipPrefixes 的栈帧(日志库内部)也会被跳过。
+func Stacktrace(skip int, skipPrefixes ...string) string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var b strings.Builder
+	inLib := true
+	for {
+		frame, more := frames.Next()
+		if inLib && hasAnyPrefix(frame.Function, skipPrefixes) {
+			if !more {
+				break
+			}
+			continue
+		}
+		inLib = false
+		if frame.Function == "runtime.goexit" {
+			break
+		}
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(frame.Function)
+		b.WriteString("\n\t")
+		b.WriteString(frame.File)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(frame.Line))
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
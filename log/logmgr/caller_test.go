@@ -0,0 +1,62 @@
+package logmgr
+
+import "testing"
+
+func TestFormatCaller(t *testing.T) {
+	const (
+		file     = "/src/app/internal/db/conn.go"
+		function = "example.com/app/internal/db.(*Conn).Query"
+	)
+	tests := []struct {
+		format   string
+		file     string
+		line     int
+		function string
+		want     string
+	}{
+		{format: "", file: file, line: 42, function: function, want: "db/conn.go:42"},
+		{format: CallerShort, file: file, line: 42, want: "db/conn.go:42"},
+		{format: CallerFull, file: file, line: 42, want: file + ":42"},
+		{format: CallerFunction, file: file, line: 42, function: function, want: "db.(*Conn).Query:42"},
+		{format: "unknown", file: file, line: 42, function: function, want: "db/conn.go:42"},
+		// 没有函数名时按 short 输出，没有文件名时输出函数名，没有行号时省略
+		{format: CallerFunction, file: file, line: 42, want: "db/conn.go:42"},
+		{format: CallerFull, line: 42, function: function, want: "db.(*Conn).Query:42"},
+		{format: CallerFunction, function: "main.main", want: "main.main"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := FormatCaller(tt.format, tt.file, tt.line, tt.function); got != tt.want {
+				t.Fatalf("FormatCaller(%q) = %q，期望 %q", tt.format, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatterCaller(t *testing.T) {
+	r := &Record{File: "/src/app/internal/db/conn.go", Line: 42, Function: "example.com/app/internal/db.(*Conn).Query"}
+	tests := []struct {
+		format   string
+		template string
+		want     string
+	}{
+		{template: "{caller}", want: "db/conn.go:42"},
+		{format: CallerFull, template: "{caller}", want: "/src/app/internal/db/conn.go:42"},
+		{format: CallerFunction, template: "{caller}", want: "db.(*Conn).Query:42"},
+		// 占位符的参数优先于 CallerFormat
+		{format: CallerFunction, template: "{caller:3}", want: "internal/db/conn.go:42"},
+		{format: CallerFunction, template: "{caller:full}", want: "/src/app/internal/db/conn.go:42"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format+tt.template, func(t *testing.T) {
+			f, err := LogConfig{Template: tt.template, CallerFormat: tt.format}.NewFormatter()
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.palette = Palette{}
+			if got := string(f.Append(nil, r)); got != tt.want+"\n" {
+				t.Fatalf("得到 %q，期望 %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package logmgr
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// Capture 按行保存写入的日志，Output 为 memory 时各后端写入 LogConfig.Capture，可并发使用
+type Capture struct {
+	mu      sync.Mutex
+	lines   [][]byte
+	partial []byte // 尚未以换行结束的内容
+}
+
+// NewCapture 创建空的日志捕获
+func NewCapture() *Capture {
+	return &Capture{}
+}
+
+// capture 进程内的全局日志捕获，LogConfig.Capture 为 nil 时写入这里
+var capture = NewCapture()
+
+func (c *Capture) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data := append(c.partial, p...)
+	for {
+		idx := bytes.IndexByte(data, '\n')
+		if idx < 0 {
+			break
+		}
+		c.lines = append(c.lines, append([]byte(nil), data[:idx]...))
+		data = data[idx+1:]
+	}
+	c.partial = append([]byte(nil), data...)
+	return len(p), nil
+}
+
+// Logs 返回捕获的日志行(不含换行)，编码由 FileEncoding 决定
+func (c *Capture) Logs() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	lines := make([]string, len(c.lines))
+	for i, l := range c.lines {
+		lines[i] = string(l)
+	}
+	return lines
+}
+
+// Entries 解析捕获的 JSON 日志，无法解析的行被忽略
+func (c *Capture) Entries() []*Entry {
+	parser, _ := LogConfig{}.NewEntryParser()
+	var entries []*Entry
+	for _, line := range c.Logs() {
+		if e, err := parser.Parse([]byte(line)); err == nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// Reset 清空捕获的日志
+func (c *Capture) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lines = nil
+	c.partial = nil
+}
+
+// CaptureWriter 返回 Output 为 memory 时写入的捕获，未设置 Capture 时为全局捕获
+func (c LogConfig) CaptureWriter() io.Writer {
+	if c.Capture != nil {
+		return c.Capture
+	}
+	return capture
+}
+
+// CaptureWriter 返回全局捕获的 writer
+func CaptureWriter() io.Writer {
+	return capture
+}
+
+// CapturedLogs 返回全局捕获的日志行(不含换行)，编码由 FileEncoding 决定
+func CapturedLogs() []string {
+	return capture.Logs()
+}
+
+// CapturedEntries 解析全局捕获的 JSON 日志，无法解析的行被忽略
+func CapturedEntries() []*Entry {
+	return capture.Entries()
+}
+
+// ResetCapture 清空全局捕获的日志，通常在每个测试开始时调用
+func ResetCapture() {
+	capture.Reset()
+}
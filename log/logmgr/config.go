@@ -1,6 +1,6 @@
 package logmgr
 
-// 颜色常量
+// 颜色常量，自定义配色请使用 Theme
 const (
 	ColorReset   = "\033[0m"
 	ColorTime    = "\033[35m" // Magenta
@@ -14,11 +14,58 @@ const (
 
 // LogConfig 日志配置
 type LogConfig struct {
-	Level      string // 日志级别: debug, info, warn, error
-	Output     string // 输出位置: console, file, both
-	FilePath   string // 日志文件路径
-	MaxSize    int    // 单个日志文件最大大小(MB)
-	MaxBackups int    // 最大保留日志文件数
-	MaxAge     int    // 最大保留天数
-	Compress   bool   // 是否压缩
+	Level           string // 日志级别: trace, debug, info, warn, error, panic, fatal，不区分大小写
+	Output          string // 输出位置: console, file, both, memory(写入内存，用于测试，见 Capture), socket(写入 Unix 套接字或命名管道), console+socket
+	FilePath        string // 日志文件路径
+	MaxSize         int    // 单个日志文件最大大小(MB)
+	MaxBackups      int    // 最大保留日志文件数
+	MaxAge          int    // 最大保留天数
+	Compress        bool   // 是否压缩
+	CompressCodec   string // 备份压缩方式: gzip(默认), zstd
+	CompressLevel   int    // 压缩级别，gzip 为 1-9，zstd 为 1-22，0 使用默认级别
+	DirMode         string // 日志目录权限，八进制如 0750，默认 0755
+	FileMode        string // 日志文件及备份的权限，八进制如 0640，默认 0600(受 umask 影响)，配置后不受 umask 影响
+	FileOwner       string // 日志文件及备份的所有者，用户名或 uid，为空时不修改，仅 Unix
+	FileGroup       string // 日志文件及备份的所属组，组名或 gid，为空时不修改，仅 Unix
+	MultiProcess    bool   // 多个进程写入同一日志文件时通过文件锁协调写入和轮转，仅支持 Linux
+	Theme           string // 控制台配色主题: default, dark, light, monochrome 或 RegisterTheme 注册的名称，未知名称使用 default
+	Template        string // 控制台行格式模板，如 "{time:15:04:05} {level:5} {caller} {msg} {fields}"，为空或无效时使用 DefaultTemplate
+	ConsoleEncoding string // 控制台编码: text, json, logfmt，为空时使用各管理器的默认编码
+	FileEncoding    string // 文件编码: json(默认), logfmt
+	TimeFormat      string // 时间格式: rfc3339, rfc3339nano, unix, unixms, unixus, unixns 或 Go 时间格式，默认 "2006-01-02 15:04:05.000"，Schema 为 ecs、gcp 时默认 rfc3339nano 和 UTC
+	TimeZone        string // 时区: local(默认), utc 或 IANA 名称如 Asia/Shanghai
+	TimeKey         string // 时间字段名，为空使用后端原生名称
+	LevelKey        string // 级别字段名，为空使用后端原生名称
+	MessageKey      string // 消息字段名，为空使用后端原生名称
+	CallerKey       string // 调用位置字段名，为空使用后端原生名称
+	Schema          string // JSON 字段映射: native(默认), ecs, gcp 或 RegisterSchema 注册的名称，TimeKey 等单独配置的字段名优先，未知名称使用 native
+	DisableCaller   bool   // 不记录调用位置
+	CallerFormat    string // 调用位置格式: short(默认), full, function，也用于控制台模板的 {caller}
+	StacktraceLevel string // 记录堆栈的最低级别，默认 error，none 表示不记录
+	RedirectStderr  bool   // 将进程的标准错误重定向到日志文件，用于记录运行时致命错误，仅在输出到文件时生效
+
+	// 飞行记录器保留的最近日志条数，0 表示不开启。开启后不低于 FlightRecorderLevel 的日志即使低于 Level 也会被编码并保留:
+	// slog 和 zap 的级别检查不再提前返回，zerolog 的全局级别放开到 FlightRecorderLevel，低于 Level 的调用同样有编码开销。
+	// 被采样丢弃的日志仍会保留，zerolog 除外(采样在编码前进行)。
+	FlightRecorderSize  int
+	FlightRecorderLevel string // 飞行记录器保留的最低级别，默认 trace(所有级别)，设为 debug 或 info 可减少开销
+	FlightRecorderFile  string // 飞行记录转储文件，为空时转储到日志文件(未输出到文件时为标准错误)
+
+	Capture *Capture // Output 为 memory 时写入的捕获，为 nil 时写入全局捕获(见 CapturedLogs)
+
+	SocketPath   string // Output 为 socket 或 console+socket 时的 Unix 套接字或命名管道路径，按 FileEncoding 编码，每行一条记录
+	SocketType   string // 套接字类型: stream(默认), datagram(每条记录一个数据报), pipe(命名管道，Unix 为 FIFO，Windows 为 \\.\pipe\<name>)
+	SocketBuffer int    // 连接断开或发送较慢时缓冲的最大记录数，默认 1024，缓冲区满时写入失败(可配合 FallbackOutput)
+
+	SamplingInitial    int // 每秒相同级别和消息的日志先全部记录的条数，0 表示不采样
+	SamplingThereafter int // 超过 SamplingInitial 后每多少条记录一条，0 表示全部丢弃
+
+	Metrics      bool // 按级别、模块和输出统计日志以及被采样丢弃的日志，通过 expvar 和 MetricsHandler 暴露；最多统计 100 个模块，之后的模块计入 other
+	ContextDebug bool // 允许通过 WithDebug 为单个请求输出 debug 日志，日志级别高于 debug 时生效；zerolog 的全局级别会放开到 debug，自行通过 zerolog.New 创建的 logger 需要设置 Level
+
+	OnWriteError   func(sink string, err error) // 输出写入失败时调用，持续失败时每秒最多调用一次；在写日志的 goroutine 中同步调用，回调中不能记录日志
+	FallbackOutput string                       // 输出写入失败时的备用输出: stderr, stdout 或文件路径，写入成功后不再返回错误；加密日志文件时备用文件同样加密，不能使用 stderr 或 stdout
+
+	EncryptionKey     string // 日志文件加密密钥(AES-GCM)，十六进制或 base64 编码的 16、24 或 32 字节，加密后需用 PrintEncryptedLog 读取
+	EncryptionKeyFile string // 从文件读取加密密钥，EncryptionKey 为空时生效
 }
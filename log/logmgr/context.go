@@ -0,0 +1,40 @@
+package logmgr
+
+import (
+	"context"
+	"log/slog"
+)
+
+type loggerKey struct{}
+
+// WithLogger 返回携带 logger 的 context
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext 返回 WithLogger 设置的 logger，没有时返回 slog.Default()
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return slog.Default()
+}
+
+type debugKey struct{}
+
+// WithDebug 返回为当前请求开启 debug 日志的 context，开启 ContextDebug 后
+// 使用该 context 记录的 debug 日志在全局级别更高时也会输出
+func WithDebug(ctx context.Context) context.Context {
+	return context.WithValue(ctx, debugKey{}, true)
+}
+
+// DebugEnabled 返回 context 是否通过 WithDebug 开启了 debug 日志
+func DebugEnabled(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	enabled, _ := ctx.Value(debugKey{}).(bool)
+	return enabled
+}
@@ -0,0 +1,180 @@
+package logmgr
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// 加密日志的分帧格式: 魔数(4) | nonce(12) | 密文长度(4，大端) | 密文(含 16 字节 GCM 标签)
+//
+// 每次写入单独成帧，文件可以直接追加，轮转也不会把一帧拆到两个文件中。
+// 超过 maxFrameSize 的写入拆分为多帧，解密时按顺序拼接。帧头作为 GCM 的附加数据参与认证。
+var frameMagic = [4]byte{'G', 'B', 'E', '1'}
+
+const (
+	frameHeaderSize = 4 + 12 + 4
+	maxFrameSize    = 64 << 20
+)
+
+// encryptWriter 将每次写入加密为一帧
+type encryptWriter struct {
+	mu   sync.Mutex
+	out  io.Writer
+	aead cipher.AEAD
+	buf  []byte
+}
+
+// NewEncryptWriter 返回使用 AES-GCM 加密写入内容的 writer，key 为 16、24 或 32 字节
+func NewEncryptWriter(w io.Writer, key []byte) (io.Writer, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptWriter{out: w, aead: aead}, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("无效的加密密钥: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func (w *encryptWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	limit := maxFrameSize - w.aead.Overhead()
+	var n int
+	for {
+		chunk := p[n:]
+		if len(chunk) > limit {
+			chunk = chunk[:limit]
+		}
+		if err := w.writeFrame(chunk); err != nil {
+			return n, err
+		}
+		if n += len(chunk); n == len(p) {
+			return n, nil
+		}
+	}
+}
+
+// writeFrame 将 p 加密为一帧写入
+func (w *encryptWriter) writeFrame(p []byte) error {
+	size := frameHeaderSize + len(p) + w.aead.Overhead()
+	if cap(w.buf) < size {
+		w.buf = make([]byte, 0, size)
+	}
+	buf := append(w.buf[:0], frameMagic[:]...)
+	buf = buf[:frameHeaderSize]
+	if _, err := rand.Read(buf[4:16]); err != nil {
+		return err
+	}
+	binary.BigEndian.PutUint32(buf[16:20], uint32(len(p)+w.aead.Overhead()))
+	buf = w.aead.Seal(buf, buf[4:16], p, buf[:frameHeaderSize])
+	_, err := w.out.Write(buf)
+	return err
+}
+
+// DecryptLog 解密 NewEncryptWriter 写入的内容并输出到 dst
+func DecryptLog(dst io.Writer, src io.Reader, key []byte) error {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+	header := make([]byte, frameHeaderSize)
+	var ciphertext, plaintext []byte
+	for frame := 1; ; frame++ {
+		if _, err := io.ReadFull(src, header); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("第 %d 帧不完整: %w", frame, err)
+		}
+		if !bytes.Equal(header[:4], frameMagic[:]) {
+			return fmt.Errorf("第 %d 帧格式错误: 不是加密日志", frame)
+		}
+		size := binary.BigEndian.Uint32(header[16:20])
+		if size > maxFrameSize {
+			return fmt.Errorf("第 %d 帧长度无效: %d", frame, size)
+		}
+		if cap(ciphertext) < int(size) {
+			ciphertext = make([]byte, size)
+		}
+		ciphertext = ciphertext[:size]
+		if _, err := io.ReadFull(src, ciphertext); err != nil {
+			return fmt.Errorf("第 %d 帧不完整: %w", frame, err)
+		}
+		plaintext, err = aead.Open(plaintext[:0], header[4:16], ciphertext, header)
+		if err != nil {
+			return fmt.Errorf("第 %d 帧解密失败(密钥错误或内容被修改)", frame)
+		}
+		if _, err := dst.Write(plaintext); err != nil {
+			return err
+		}
+	}
+}
+
+// ParseEncryptionKey 解析十六进制或 base64 编码的 AES 密钥
+func ParseEncryptionKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if key, err := hex.DecodeString(s); err == nil && validKeyLen(len(key)) {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(s); err == nil && validKeyLen(len(key)) {
+		return key, nil
+	}
+	return nil, errors.New("加密密钥应为十六进制或 base64 编码的 16、24 或 32 字节")
+}
+
+func validKeyLen(n int) bool {
+	return n == 16 || n == 24 || n == 32
+}
+
+// GetEncryptionKey 返回配置的加密密钥，未配置时返回 nil
+func (c LogConfig) GetEncryptionKey() ([]byte, error) {
+	switch {
+	case c.EncryptionKey != "":
+		return ParseEncryptionKey(c.EncryptionKey)
+	case c.EncryptionKeyFile != "":
+		data, err := os.ReadFile(c.EncryptionKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取加密密钥失败: %w", err)
+		}
+		return ParseEncryptionKey(string(data))
+	}
+	return nil, nil
+}
+
+// PrintEncryptedLog 按时间顺序解密 path 及其备份文件(包括 .gz)并输出到 dst
+func PrintEncryptedLog(dst io.Writer, path string, key []byte) error {
+	files, err := LogFiles(path)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		f, err := OpenLogFile(file)
+		if err != nil {
+			return err
+		}
+		err = DecryptLog(dst, f, key)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package logmgr
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEncryptRoundTrip(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	tests := []struct {
+		name   string
+		writes [][]byte
+		frames int
+	}{
+		{name: "lines", writes: [][]byte{[]byte("line 1\n"), []byte("line 2\n")}, frames: 2},
+		{name: "empty", writes: [][]byte{{}}, frames: 1},
+		{
+			// 超过单帧上限的写入拆分为多帧
+			name:   "large",
+			writes: [][]byte{bytes.Repeat([]byte("x"), maxFrameSize+100), []byte("tail\n")},
+			frames: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var enc bytes.Buffer
+			w, err := NewEncryptWriter(&enc, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want []byte
+			for _, p := range tt.writes {
+				n, err := w.Write(p)
+				if err != nil || n != len(p) {
+					t.Fatalf("写入 %d 字节返回 %d, %v", len(p), n, err)
+				}
+				want = append(want, p...)
+			}
+			if got := bytes.Count(enc.Bytes(), frameMagic[:]); got < tt.frames {
+				t.Fatalf("%d 帧，期望 %d 帧", got, tt.frames)
+			}
+
+			var dec bytes.Buffer
+			if err := DecryptLog(&dec, bytes.NewReader(enc.Bytes()), key); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(dec.Bytes(), want) {
+				t.Fatalf("解密得到 %d 字节，期望 %d 字节", dec.Len(), len(want))
+			}
+		})
+	}
+}
+
+func TestDecryptLogErrors(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 16)
+	var enc bytes.Buffer
+	w, _ := NewEncryptWriter(&enc, key)
+	w.Write([]byte("first\n"))
+	w.Write([]byte("second\n"))
+	data := enc.Bytes()
+	frame := frameHeaderSize + len("first\n") + 16
+
+	tests := []struct {
+		name string
+		data []byte
+		key  []byte
+		want string // 错误信息
+		out  string // 出错前已解密的内容
+	}{
+		{name: "truncated header", data: data[:frame+5], want: "第 2 帧不完整", out: "first\n"},
+		{name: "truncated body", data: data[:len(data)-3], want: "第 2 帧不完整", out: "first\n"},
+		{name: "bad magic", data: append([]byte("XXXX"), data[4:]...), want: "第 1 帧格式错误"},
+		{name: "wrong key", data: data, key: bytes.Repeat([]byte{8}, 16), want: "第 1 帧解密失败"},
+		{
+			name: "modified",
+			data: func() []byte {
+				d := bytes.Clone(data)
+				d[frame+frameHeaderSize] ^= 1
+				return d
+			}(),
+			want: "第 2 帧解密失败",
+			out:  "first\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := key
+			if tt.key != nil {
+				k = tt.key
+			}
+			var out bytes.Buffer
+			err := DecryptLog(&out, bytes.NewReader(tt.data), k)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("错误 %v，期望 %q", err, tt.want)
+			}
+			if out.String() != tt.out {
+				t.Fatalf("已解密 %q，期望 %q", out.String(), tt.out)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package logmgr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Entry 从 JSON 日志行解析出的记录
+type Entry struct {
+	Time    time.Time // 没有时间字段或无法解析时为零值
+	Level   Level
+	Message string
+	Caller  string                 // 调用位置原文，如 dir/file.go:12
+	Stack   string                 // 堆栈
+	Fields  map[string]interface{} // 其余字段，数字为 json.Number
+}
+
+// 各后端原生及内置 Schema 的字段名，按优先级排列
+var (
+	entryTimeKeys    = []string{"time", "timestamp", "ts", "@timestamp"}
+	entryLevelKeys   = []string{"level", "lvl", "log.level", "severity"}
+	entryMessageKeys = []string{"msg", "message"}
+	entryCallerKeys  = []string{"source", "caller", "log.origin.file.name"}
+	entryStackKeys   = []string{StacktraceKey, "error.stack_trace", "stack_trace"}
+)
+
+// EntryParser 解析 slogmgr、zaplogmgr 和 zerologmgr 输出的 JSON 日志行
+type EntryParser struct {
+	keys    FieldKeys   // 配置的字段名，为空的字段按原生名称识别
+	schema  Schema      // 解析级别取值
+	timeEnc TimeEncoder // 解析文本时间，失败时再尝试 RFC 3339
+}
+
+// NewEntryParser 按配置的字段名、Schema 和时间格式创建解析器，零值配置可识别三个后端的默认输出和内置 Schema 的字段名
+func (c LogConfig) NewEntryParser() (*EntryParser, error) {
+	timeEnc, err := c.NewTimeEncoder()
+	if err != nil {
+		return nil, err
+	}
+	return &EntryParser{keys: c.FieldKeys(FieldKeys{}), schema: c.GetSchema(), timeEnc: timeEnc}, nil
+}
+
+// Parse 解析一行 JSON 日志
+func (p *EntryParser) Parse(line []byte) (*Entry, error) {
+	var fields map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(line))
+	d.UseNumber()
+	if err := d.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("无法解析日志行: %w", err)
+	}
+
+	e := &Entry{Fields: fields}
+	if v, ok := takeField(fields, p.keys.Time, entryTimeKeys); ok {
+		e.Time = p.parseTime(v)
+	}
+	if v, ok := takeField(fields, p.keys.Level, entryLevelKeys); ok {
+		e.Level, _ = p.schema.ParseLevel(FieldString(v))
+	}
+	if v, ok := takeField(fields, p.keys.Message, entryMessageKeys); ok {
+		e.Message = FieldString(v)
+	}
+	if v, ok := takeField(fields, p.keys.Caller, entryCallerKeys); ok {
+		e.Caller = FieldString(v)
+	}
+	if v, ok := takeField(fields, p.keys.Stacktrace, entryStackKeys); ok {
+		e.Stack = FieldString(v)
+	}
+	return e, nil
+}
+
+// takeField 取出并删除 key 对应的字段，key 为空时依次尝试 candidates
+func takeField(fields map[string]interface{}, key string, candidates []string) (interface{}, bool) {
+	if key != "" {
+		candidates = []string{key}
+	}
+	for _, k := range candidates {
+		if v, ok := fields[k]; ok {
+			delete(fields, k)
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// parseTime 解析文本时间或 Unix 时间戳(按数值大小判断单位)
+func (p *EntryParser) parseTime(v interface{}) time.Time {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return unixAuto(n).In(p.timeEnc.Location())
+		}
+		if f, err := v.Float64(); err == nil {
+			// zap 默认的秒级浮点时间
+			return time.Unix(0, int64(f*float64(time.Second))).In(p.timeEnc.Location())
+		}
+	case string:
+		if t, err := p.timeEnc.Parse(v); err == nil {
+			return t
+		}
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t
+		}
+		if t, err := time.ParseInLocation(DefaultTimeLayout, v, p.timeEnc.Location()); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// unixAuto 将秒、毫秒、微秒或纳秒时间戳转换为时间
+func unixAuto(n int64) time.Time {
+	switch {
+	case n < 1e11:
+		return time.Unix(n, 0)
+	case n < 1e14:
+		return time.UnixMilli(n)
+	case n < 1e17:
+		return time.UnixMicro(n)
+	}
+	return time.Unix(0, n)
+}
+
+// CallerLocation 将调用位置拆分为文件和行号，没有行号时 line 为 0
+func (e *Entry) CallerLocation() (file string, line int) {
+	if idx := strings.LastIndexByte(e.Caller, ':'); idx > 0 {
+		if n, err := strconv.Atoi(e.Caller[idx+1:]); err == nil {
+			return e.Caller[:idx], n
+		}
+	}
+	return e.Caller, 0
+}
+
+// FieldString 将 JSON 值转换为文本，对象和数组输出为 JSON
+func FieldString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
@@ -0,0 +1,257 @@
+package logmgr
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/rs/zerolog"
+	"go.uber.org/zap/zapcore"
+)
+
+// LogFielder 由携带日志字段的错误实现，字段由 Err 输出
+type LogFielder interface {
+	LogFields() map[string]interface{}
+}
+
+// StackTracer 由记录了调用栈的错误实现(如 errutil 创建的错误)，调用栈由 Err 输出
+type StackTracer interface {
+	StackTrace() string
+}
+
+// 错误链的最大输出深度
+const maxErrorDepth = 16
+
+// ErrorValue 结构化输出的错误，由 Err 创建
+type ErrorValue struct {
+	err error
+}
+
+// Err 返回结构化输出的错误: 错误信息 msg、类型 type、包装的错误 cause(errors.Join 为 causes)、
+// 错误链中最内层 StackTracer 记录的调用栈 stack，以及错误链中 LogFielder 携带的字段(外层优先)
+//
+//	slog.Error("保存失败", "error", logmgr.Err(err))
+//	zap.L().Error("保存失败", zap.Object("error", logmgr.Err(err)))
+//	log.Error().Object("error", logmgr.Err(err)).Msg("保存失败")
+func Err(err error) ErrorValue {
+	return ErrorValue{err: err}
+}
+
+// Error 返回错误信息
+func (v ErrorValue) Error() string {
+	if v.err == nil {
+		return "<nil>"
+	}
+	return v.err.Error()
+}
+
+// Unwrap 返回原始错误
+func (v ErrorValue) Unwrap() error {
+	return v.err
+}
+
+// errorNode 错误链中的一个错误，信息相同的包装(如只记录调用栈或字段的错误)合并为一个节点
+type errorNode struct {
+	msg    string
+	typ    string
+	cause  *errorNode
+	causes []*errorNode
+	stack  string      // 仅根节点和 errors.Join 的分支
+	fields []errorAttr // 仅根节点
+}
+
+type errorAttr struct {
+	key   string
+	value interface{}
+}
+
+// 内置字段名，错误携带的同名字段被忽略
+var errorBuiltinKeys = map[string]bool{"msg": true, "type": true, "cause": true, "causes": true, "stack": true}
+
+// tree 构建错误链
+func (v ErrorValue) tree() *errorNode {
+	if v.err == nil {
+		return nil
+	}
+	fields := map[string]interface{}{}
+	root := newErrorNode(v.err, 0, fields)
+	for k, val := range fields {
+		root.fields = append(root.fields, errorAttr{k, val})
+	}
+	sort.Slice(root.fields, func(i, j int) bool { return root.fields[i].key < root.fields[j].key })
+	root.stack = innermostStack(v.err)
+	return root
+}
+
+// newErrorNode 构建 err 对应的节点，fields 收集错误携带的字段
+func newErrorNode(err error, depth int, fields map[string]interface{}) *errorNode {
+	n := &errorNode{msg: err.Error(), typ: fmt.Sprintf("%T", err)}
+	for {
+		if f, ok := err.(LogFielder); ok {
+			for k, val := range f.LogFields() {
+				if _, exists := fields[k]; !exists && !errorBuiltinKeys[k] {
+					fields[k] = val
+				}
+			}
+		}
+		switch u := err.(type) {
+		case interface{ Unwrap() error }:
+			next := u.Unwrap()
+			if next == nil {
+				return n
+			}
+			if next.Error() == n.msg {
+				err = next
+				continue
+			}
+			if depth+1 < maxErrorDepth {
+				n.cause = newErrorNode(next, depth+1, fields)
+			}
+		case interface{ Unwrap() []error }:
+			if depth+1 < maxErrorDepth {
+				for _, e := range u.Unwrap() {
+					if e != nil {
+						c := newErrorNode(e, depth+1, fields)
+						c.stack = innermostStack(e)
+						n.causes = append(n.causes, c)
+					}
+				}
+			}
+		}
+		return n
+	}
+}
+
+// innermostStack 返回错误链(不含 errors.Join 的分支)中最内层记录的调用栈
+func innermostStack(err error) string {
+	var stack string
+	for err != nil {
+		if st, ok := err.(StackTracer); ok {
+			if s := st.StackTrace(); s != "" {
+				stack = s
+			}
+		}
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+		err = u.Unwrap()
+	}
+	return stack
+}
+
+// LogValue 实现 slog.LogValuer
+func (v ErrorValue) LogValue() slog.Value {
+	n := v.tree()
+	if n == nil {
+		return slog.StringValue("<nil>")
+	}
+	return slog.GroupValue(n.slogAttrs()...)
+}
+
+func (n *errorNode) slogAttrs() []slog.Attr {
+	attrs := []slog.Attr{slog.String("msg", n.msg), slog.String("type", n.typ)}
+	for _, f := range n.fields {
+		attrs = append(attrs, slog.Any(f.key, f.value))
+	}
+	if n.cause != nil {
+		attrs = append(attrs, slog.Attr{Key: "cause", Value: slog.GroupValue(n.cause.slogAttrs()...)})
+	}
+	if len(n.causes) > 0 {
+		// slog 没有数组类型，使用可被 JSON 编码的切片
+		causes := make([]interface{}, len(n.causes))
+		for i, c := range n.causes {
+			causes[i] = c.toMap()
+		}
+		attrs = append(attrs, slog.Any("causes", causes))
+	}
+	if n.stack != "" {
+		attrs = append(attrs, slog.String("stack", n.stack))
+	}
+	return attrs
+}
+
+func (n *errorNode) toMap() map[string]interface{} {
+	m := map[string]interface{}{"msg": n.msg, "type": n.typ}
+	if n.cause != nil {
+		m["cause"] = n.cause.toMap()
+	}
+	if len(n.causes) > 0 {
+		causes := make([]interface{}, len(n.causes))
+		for i, c := range n.causes {
+			causes[i] = c.toMap()
+		}
+		m["causes"] = causes
+	}
+	if n.stack != "" {
+		m["stack"] = n.stack
+	}
+	return m
+}
+
+// MarshalLogObject 实现 zapcore.ObjectMarshaler
+func (v ErrorValue) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	if n := v.tree(); n != nil {
+		return n.MarshalLogObject(enc)
+	}
+	return nil
+}
+
+func (n *errorNode) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("msg", n.msg)
+	enc.AddString("type", n.typ)
+	for _, f := range n.fields {
+		if err := enc.AddReflected(f.key, f.value); err != nil {
+			enc.AddString(f.key, fmt.Sprint(f.value))
+		}
+	}
+	if n.cause != nil {
+		if err := enc.AddObject("cause", n.cause); err != nil {
+			return err
+		}
+	}
+	if len(n.causes) > 0 {
+		err := enc.AddArray("causes", zapcore.ArrayMarshalerFunc(func(ae zapcore.ArrayEncoder) error {
+			for _, c := range n.causes {
+				if err := ae.AppendObject(c); err != nil {
+					return err
+				}
+			}
+			return nil
+		}))
+		if err != nil {
+			return err
+		}
+	}
+	if n.stack != "" {
+		enc.AddString("stack", n.stack)
+	}
+	return nil
+}
+
+// MarshalZerologObject 实现 zerolog.LogObjectMarshaler
+func (v ErrorValue) MarshalZerologObject(e *zerolog.Event) {
+	if n := v.tree(); n != nil {
+		n.MarshalZerologObject(e)
+	}
+}
+
+func (n *errorNode) MarshalZerologObject(e *zerolog.Event) {
+	e.Str("msg", n.msg).Str("type", n.typ)
+	for _, f := range n.fields {
+		e.Interface(f.key, f.value)
+	}
+	if n.cause != nil {
+		e.Object("cause", n.cause)
+	}
+	if len(n.causes) > 0 {
+		arr := zerolog.Arr()
+		for _, c := range n.causes {
+			arr.Object(c)
+		}
+		e.Array("causes", arr)
+	}
+	if n.stack != "" {
+		e.Str("stack", n.stack)
+	}
+}
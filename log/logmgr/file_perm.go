@@ -0,0 +1,183 @@
+package logmgr
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// 默认权限，与 lumberjack 相同
+const (
+	defaultDirMode  os.FileMode = 0755
+	defaultFileMode os.FileMode = 0600
+)
+
+// 备份压缩方式
+const (
+	CodecGzip = "gzip"
+	CodecZstd = "zstd"
+)
+
+// filePerm 日志目录和文件的权限及所有者
+type filePerm struct {
+	dirMode  os.FileMode
+	fileMode os.FileMode
+	chmod    bool // 配置了 FileMode，创建后显式设置权限以忽略 umask
+	uid, gid int  // -1 表示不修改
+}
+
+// defaultFilePerm 返回默认权限，不修改所有者
+func defaultFilePerm() filePerm {
+	return filePerm{dirMode: defaultDirMode, fileMode: defaultFileMode, uid: -1, gid: -1}
+}
+
+// filePerm 解析 DirMode、FileMode、FileOwner 和 FileGroup
+func (c LogConfig) filePerm() (filePerm, error) {
+	p := defaultFilePerm()
+	var err error
+	if c.DirMode != "" {
+		if p.dirMode, err = parseFileMode(c.DirMode); err != nil {
+			return p, fmt.Errorf("日志目录权限无效: %w", err)
+		}
+	}
+	if c.FileMode != "" {
+		if p.fileMode, err = parseFileMode(c.FileMode); err != nil {
+			return p, fmt.Errorf("日志文件权限无效: %w", err)
+		}
+		p.chmod = true
+	}
+	if c.FileOwner != "" {
+		if p.uid, err = lookupID(c.FileOwner, func(name string) (string, error) {
+			u, err := user.Lookup(name)
+			if err != nil {
+				return "", err
+			}
+			return u.Uid, nil
+		}); err != nil {
+			return p, fmt.Errorf("日志文件所有者无效: %w", err)
+		}
+	}
+	if c.FileGroup != "" {
+		if p.gid, err = lookupID(c.FileGroup, func(name string) (string, error) {
+			g, err := user.LookupGroup(name)
+			if err != nil {
+				return "", err
+			}
+			return g.Gid, nil
+		}); err != nil {
+			return p, fmt.Errorf("日志文件所属组无效: %w", err)
+		}
+	}
+	return p, nil
+}
+
+// parseFileMode 解析八进制权限，如 0640、640
+func parseFileMode(s string) (os.FileMode, error) {
+	m, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimSpace(s), "0o"), 8, 32)
+	if err != nil || m > 0777 {
+		return 0, fmt.Errorf("%q 不是八进制权限", s)
+	}
+	return os.FileMode(m), nil
+}
+
+// lookupID 解析数字 id 或按名称查找
+func lookupID(s string, lookup func(string) (string, error)) (int, error) {
+	if id, err := strconv.Atoi(s); err == nil && id >= 0 {
+		return id, nil
+	}
+	id, err := lookup(s)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(id)
+}
+
+// mkdir 按配置的权限创建目录
+func (p filePerm) mkdir(dir string) error {
+	return os.MkdirAll(dir, p.dirMode)
+}
+
+// openFile 按配置的权限和所有者打开文件
+func (p filePerm) openFile(path string, flag int) (*os.File, error) {
+	f, err := os.OpenFile(path, flag, p.fileMode)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.apply(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// apply 设置已打开文件的权限和所有者
+func (p filePerm) apply(f *os.File) error {
+	if p.chmod {
+		if err := f.Chmod(p.fileMode); err != nil {
+			return err
+		}
+	}
+	if p.uid >= 0 || p.gid >= 0 {
+		return f.Chown(p.uid, p.gid)
+	}
+	return nil
+}
+
+// codec 备份文件的压缩方式
+type codec struct {
+	name  string
+	level int
+}
+
+// codec 解析 CompressCodec 和 CompressLevel
+func (c LogConfig) codec() (codec, error) {
+	cd := codec{name: strings.ToLower(strings.TrimSpace(c.CompressCodec)), level: c.CompressLevel}
+	switch cd.name {
+	case "", CodecGzip:
+		cd.name = CodecGzip
+		if cd.level != 0 && (cd.level < gzip.BestSpeed || cd.level > gzip.BestCompression) {
+			return cd, fmt.Errorf("gzip 压缩级别应为 1-9: %d", cd.level)
+		}
+	case CodecZstd:
+		if cd.level < 0 || cd.level > 22 {
+			return cd, fmt.Errorf("zstd 压缩级别应为 1-22: %d", cd.level)
+		}
+	default:
+		return cd, fmt.Errorf("未知的压缩方式: %q", c.CompressCodec)
+	}
+	return cd, nil
+}
+
+// builtin 是否为 lumberjack 自带的压缩方式(默认级别的 gzip)
+func (cd codec) builtin() bool {
+	return cd.name == CodecGzip && cd.level == 0
+}
+
+// ext 返回压缩文件的扩展名
+func (cd codec) ext() string {
+	if cd.name == CodecZstd {
+		return ".zst"
+	}
+	return ".gz"
+}
+
+// newWriter 创建压缩写入器
+func (cd codec) newWriter(w io.Writer) (io.WriteCloser, error) {
+	if cd.name == CodecZstd {
+		level := zstd.SpeedDefault
+		if cd.level > 0 {
+			level = zstd.EncoderLevelFromZstd(cd.level)
+		}
+		return zstd.NewWriter(w, zstd.WithEncoderLevel(level))
+	}
+	if cd.level == 0 {
+		return gzip.NewWriter(w), nil
+	}
+	return gzip.NewWriterLevel(w, cd.level)
+}
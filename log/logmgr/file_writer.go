@@ -0,0 +1,81 @@
+package logmgr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// NewFileWriter 按配置创建日志文件的 writer: 使用 lumberjack 轮转(多进程模式下使用文件锁)，配置了密钥时加密写入，
+// 配置了 RedirectStderr 时将标准错误重定向到该文件
+func (c LogConfig) NewFileWriter() (io.Writer, error) {
+	if c.RedirectStderr && c.Encrypted() {
+		// 标准错误直接写入文件，会破坏加密文件的分帧
+		return nil, errors.New("加密日志文件时不能重定向标准错误")
+	}
+	f, err := c.NewRotatingFile()
+	if err != nil {
+		return nil, err
+	}
+	if c.RedirectStderr {
+		if err := c.redirectStderr(f); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("重定向标准错误失败: %w", err)
+		}
+	}
+	w, err := c.encrypt(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// NewRotatingFile 按配置打开轮转的日志文件(不加密)，目录和文件按 DirMode、FileMode 等设置权限和所有者
+func (c LogConfig) NewRotatingFile() (io.WriteCloser, error) {
+	if c.MultiProcess {
+		return newSharedFile(c)
+	}
+	return newLumberjackFile(c)
+}
+
+// redirectStderr 按配置的权限和所有者打开日志文件并重定向标准错误，
+// 打开时持有 f 的锁，避免打开正在被轮转(多进程模式下包括其他进程的轮转)的文件
+func (c LogConfig) redirectStderr(f io.WriteCloser) error {
+	perm, err := c.filePerm()
+	if err != nil {
+		return err
+	}
+	redirect := func() error {
+		return redirectStderr(perm, c.FilePath)
+	}
+	if lf, ok := f.(interface{ locked(func() error) error }); ok {
+		return lf.locked(redirect)
+	}
+	return redirect()
+}
+
+// redirectStderr 按 perm 打开 path 并重定向标准错误
+func redirectStderr(perm filePerm, path string) error {
+	f, err := perm.openFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return dupStderr(f)
+}
+
+// Encrypted 是否配置了日志文件加密
+func (c LogConfig) Encrypted() bool {
+	return c.EncryptionKey != "" || c.EncryptionKeyFile != ""
+}
+
+// encrypt 配置了密钥时返回加密的 writer，否则原样返回
+func (c LogConfig) encrypt(w io.Writer) (io.Writer, error) {
+	key, err := c.GetEncryptionKey()
+	if err != nil || key == nil {
+		return w, err
+	}
+	return NewEncryptWriter(w, key)
+}
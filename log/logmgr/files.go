@@ -0,0 +1,152 @@
+package logmgr
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// lumberjack 备份文件名中的时间格式
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// LogFiles 返回日志文件及其备份，按时间从旧到新排序，当前文件在最后(不存在时不包含)
+func LogFiles(path string) ([]string, error) {
+	backups, err := backupFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(backups)+1)
+	for _, b := range backups {
+		files = append(files, b.path)
+	}
+	if _, err := os.Stat(path); err == nil {
+		files = append(files, path)
+	}
+	return files, nil
+}
+
+// backupFile 备份文件及文件名中的轮转时间
+type backupFile struct {
+	path string
+	t    time.Time
+}
+
+// backupFiles 返回 path 的备份文件，按时间从旧到新排序
+func backupFiles(path string) ([]backupFile, error) {
+	dir := filepath.Dir(path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	names := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		names[e.Name()] = true
+	}
+	var backups []backupFile
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		t, ok := BackupTime(path, e.Name())
+		if !ok {
+			continue
+		}
+		if n := e.Name(); n != trimCompressExt(n) && names[trimCompressExt(n)] {
+			// 压缩未完成(如进程在压缩时退出)，使用未压缩的文件
+			continue
+		}
+		backups = append(backups, backupFile{path: filepath.Join(dir, e.Name()), t: t})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].t.Before(backups[j].t) })
+	return backups, nil
+}
+
+// removeOldBackups 按 MaxAge 和 MaxBackups 删除旧备份，忽略已被删除的文件
+func removeOldBackups(c LogConfig) {
+	backups, err := backupFiles(c.FilePath)
+	if err != nil {
+		return
+	}
+	if c.MaxAge > 0 {
+		cutoff := time.Now().Add(-time.Duration(c.MaxAge) * 24 * time.Hour)
+		for len(backups) > 0 && backups[0].t.Before(cutoff) {
+			os.Remove(backups[0].path)
+			backups = backups[1:]
+		}
+	}
+	if c.MaxBackups > 0 {
+		for len(backups) > c.MaxBackups {
+			os.Remove(backups[0].path)
+			backups = backups[1:]
+		}
+	}
+}
+
+// BackupTime 返回 path 的备份文件 file 的轮转时间，即其中最后一条记录之后的时间，file 不是备份文件时 ok 为 false
+func BackupTime(path, file string) (t time.Time, ok bool) {
+	name := filepath.Base(path)
+	ext := filepath.Ext(name)
+	prefix := name[:len(name)-len(ext)] + "-"
+	n := trimCompressExt(filepath.Base(file))
+	if !strings.HasPrefix(n, prefix) || !strings.HasSuffix(n, ext) || len(n) < len(prefix)+len(ext) {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(backupTimeFormat, n[len(prefix):len(n)-len(ext)])
+	return t, err == nil
+}
+
+// trimCompressExt 去掉压缩备份的扩展名 .gz 或 .zst
+func trimCompressExt(name string) string {
+	for _, ext := range []string{".gz", ".zst"} {
+		if strings.HasSuffix(name, ext) {
+			return name[:len(name)-len(ext)]
+		}
+	}
+	return name
+}
+
+// OpenLogFile 打开日志文件，.gz 和 .zst 文件自动解压
+func OpenLogFile(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case strings.HasSuffix(path, ".gz"):
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		return &compressedFile{Reader: zr, zr: zr, f: f}, nil
+	case strings.HasSuffix(path, ".zst"):
+		zr, err := zstd.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		return &compressedFile{Reader: zr, zr: zr.IOReadCloser(), f: f}, nil
+	}
+	return f, nil
+}
+
+// compressedFile 关闭时同时关闭解压器和文件
+type compressedFile struct {
+	io.Reader
+	zr io.Closer
+	f  *os.File
+}
+
+func (c *compressedFile) Close() error {
+	c.zr.Close()
+	return c.f.Close()
+}
@@ -0,0 +1,175 @@
+package logmgr
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// FlightRecorder 飞行记录器，在内存中保留最近的若干条已编码日志(不受日志级别限制)，
+// 记录到 error 及以上级别的日志或手动调用 Dump 时将其写入转储目标
+type FlightRecorder struct {
+	mu       sync.Mutex
+	entries  []flightEntry // 环形缓冲
+	next     int           // 下一条写入的位置
+	count    int           // 当前保留的条数
+	out      io.Writer
+	skipLive bool // 转储时跳过已经写入实时输出的记录
+}
+
+type flightEntry struct {
+	level Level
+	line  []byte
+	live  bool // 已经写入实时输出
+}
+
+// NewFlightRecorder 创建保留最近 size 条记录的飞行记录器，转储时写入 out
+func NewFlightRecorder(size int, out io.Writer) *FlightRecorder {
+	if size <= 0 {
+		size = 1
+	}
+	return &FlightRecorder{entries: make([]flightEntry, size), out: out}
+}
+
+// SkipLive 设置转储时跳过已经写入实时输出的记录，用于转储到实时输出本身，避免重复
+func (r *FlightRecorder) SkipLive() *FlightRecorder {
+	r.mu.Lock()
+	r.skipLive = true
+	r.mu.Unlock()
+	return r
+}
+
+// Add 保存一条已编码的日志，live 表示该记录同时写入了实时输出(包括为请求开启的 debug 日志)，
+// 级别不低于 ErrorLevel 时触发转储
+func (r *FlightRecorder) Add(level Level, line []byte, live bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e := &r.entries[r.next]
+	e.level = level
+	e.live = live
+	e.line = append(e.line[:0], line...) // 复用缓冲
+	r.next = (r.next + 1) % len(r.entries)
+	if r.count < len(r.entries) {
+		r.count++
+	}
+	if level >= ErrorLevel {
+		_, _ = r.dump()
+	}
+}
+
+// Dump 按时间顺序写出保留的记录并清空缓冲，返回写出的条数
+func (r *FlightRecorder) Dump() (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.dump()
+}
+
+func (r *FlightRecorder) dump() (int, error) {
+	start := (r.next - r.count + len(r.entries)) % len(r.entries)
+	n := 0
+	var err error
+	for i := 0; i < r.count; i++ {
+		e := &r.entries[(start+i)%len(r.entries)]
+		if r.skipLive && e.live {
+			continue
+		}
+		if _, werr := r.out.Write(e.line); werr != nil {
+			err = werr
+			break
+		}
+		n++
+	}
+	r.count = 0
+	return n, err
+}
+
+// NewFlightRecorder 按配置创建飞行记录器，未开启时返回 nil
+//
+// fileSink 为日志文件输出，未输出到文件时为 nil。配置了 FlightRecorderFile 时转储到该文件，
+// 否则转储到 fileSink 或标准错误，此时只转储没有写入实时输出的记录。
+func (c LogConfig) NewFlightRecorder(fileSink io.Writer) (*FlightRecorder, error) {
+	if c.FlightRecorderSize <= 0 {
+		return nil, nil
+	}
+	if c.FlightRecorderFile != "" {
+		perm, err := c.filePerm()
+		if err != nil {
+			return nil, err
+		}
+		if err := perm.mkdir(filepath.Dir(c.FlightRecorderFile)); err != nil {
+			return nil, err
+		}
+		f, err := perm.openFile(c.FlightRecorderFile, os.O_WRONLY|os.O_APPEND|os.O_CREATE)
+		if err != nil {
+			return nil, err
+		}
+		// 与日志文件使用相同的加密设置
+		out, err := c.encrypt(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		return NewFlightRecorder(c.FlightRecorderSize, out), nil
+	}
+	out := fileSink
+	if out == nil {
+		out = os.Stderr
+	}
+	return NewFlightRecorder(c.FlightRecorderSize, out).SkipLive(), nil
+}
+
+// GetFlightRecorderLevel 返回飞行记录器保留的最低级别，默认 TraceLevel(无法解析时同样)
+func (c LogConfig) GetFlightRecorderLevel() Level {
+	if strings.TrimSpace(c.FlightRecorderLevel) == "" {
+		return TraceLevel
+	}
+	l, err := ParseLevel(c.FlightRecorderLevel)
+	if err != nil {
+		return TraceLevel
+	}
+	return l
+}
+
+var (
+	flightMu       sync.Mutex
+	flightRecorder *FlightRecorder
+)
+
+// SetFlightRecorder 设置当前使用的飞行记录器，由各日志管理器在 Setup 时调用，nil 表示未开启
+func SetFlightRecorder(r *FlightRecorder) {
+	flightMu.Lock()
+	flightRecorder = r
+	flightMu.Unlock()
+}
+
+// DumpFlightRecorder 转储当前飞行记录器中的记录，未开启时返回 0
+func DumpFlightRecorder() (int, error) {
+	flightMu.Lock()
+	r := flightRecorder
+	flightMu.Unlock()
+	if r == nil {
+		return 0, nil
+	}
+	return r.Dump()
+}
+
+// FlightRecorderHandler 返回触发转储的 HTTP 处理器，支持 GET 和 POST
+func FlightRecorderHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodPost {
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "不支持的请求方法", http.StatusMethodNotAllowed)
+			return
+		}
+		n, err := DumpFlightRecorder()
+		if err != nil {
+			http.Error(w, "转储失败: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintf(w, "已转储 %d 条记录\n", n)
+	})
+}
@@ -0,0 +1,123 @@
+package logmgr
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFlightRecorder(t *testing.T) {
+	type add struct {
+		level Level
+		line  string
+		live  bool
+	}
+	tests := []struct {
+		name     string
+		size     int
+		skipLive bool
+		adds     []add
+		want     string // 转储的内容
+	}{
+		{
+			name: "dump on error",
+			size: 10,
+			adds: []add{{DebugLevel, "d1\n", false}, {InfoLevel, "i1\n", false}, {ErrorLevel, "e1\n", false}},
+			want: "d1\ni1\ne1\n",
+		},
+		{
+			name: "ring",
+			size: 2,
+			adds: []add{{DebugLevel, "d1\n", false}, {DebugLevel, "d2\n", false}, {DebugLevel, "d3\n", false}, {ErrorLevel, "e1\n", false}},
+			want: "d3\ne1\n",
+		},
+		{
+			name: "cleared after dump",
+			size: 10,
+			adds: []add{{DebugLevel, "d1\n", false}, {ErrorLevel, "e1\n", false}, {DebugLevel, "d2\n", false}, {FatalLevel, "f1\n", false}},
+			want: "d1\ne1\nd2\nf1\n",
+		},
+		{
+			name:     "skip live",
+			size:     10,
+			skipLive: true,
+			adds: []add{
+				{DebugLevel, "d1\n", false}, {InfoLevel, "i1\n", true},
+				{DebugLevel, "d2\n", true}, // 为请求开启 debug 时已经写入实时输出
+				{TraceLevel, "t1\n", false}, {ErrorLevel, "e1\n", true},
+			},
+			want: "d1\nt1\n",
+		},
+		{
+			name: "keep live",
+			size: 10,
+			adds: []add{{DebugLevel, "d1\n", true}, {ErrorLevel, "e1\n", true}},
+			want: "d1\ne1\n",
+		},
+		{
+			name: "no error",
+			size: 10,
+			adds: []add{{DebugLevel, "d1\n", false}, {WarnLevel, "w1\n", false}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			r := NewFlightRecorder(tt.size, &out)
+			if tt.skipLive {
+				r.SkipLive()
+			}
+			for _, a := range tt.adds {
+				r.Add(a.level, []byte(a.line), a.live)
+			}
+			if got := out.String(); got != tt.want {
+				t.Fatalf("转储 %q，期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlightRecorderDump(t *testing.T) {
+	var out bytes.Buffer
+	r := NewFlightRecorder(3, &out)
+	for _, line := range []string{"a\n", "b\n", "c\n", "d\n"} {
+		r.Add(DebugLevel, []byte(line), false)
+	}
+	if n, err := r.Dump(); n != 3 || err != nil {
+		t.Fatalf("Dump() = %d, %v", n, err)
+	}
+	if n, _ := r.Dump(); n != 0 {
+		t.Fatalf("再次 Dump() = %d，期望 0", n)
+	}
+	if got := out.String(); got != "b\nc\nd\n" {
+		t.Fatalf("转储 %q", got)
+	}
+}
+
+func TestValidateFlightRecorderLevel(t *testing.T) {
+	tests := []struct {
+		level   string
+		want    Level
+		wantErr bool
+	}{
+		{level: "", want: TraceLevel},
+		{level: "debug", want: DebugLevel},
+		{level: "INFO", want: InfoLevel},
+		{level: "verbose", want: TraceLevel, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			c := LogConfig{FlightRecorderLevel: tt.level}
+			err := c.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v，期望错误: %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "飞行记录器") {
+				t.Fatalf("错误信息: %v", err)
+			}
+			if got := c.Checked().GetFlightRecorderLevel(); got != tt.want {
+				t.Fatalf("GetFlightRecorderLevel() = %v，期望 %v", got, tt.want)
+			}
+		})
+	}
+}
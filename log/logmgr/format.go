@@ -0,0 +1,306 @@
+package logmgr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTemplate 默认控制台行格式
+const DefaultTemplate = "[{time}] [{level}] {caller} {msg} {fields}"
+
+// DefaultTimeLayout 默认时间格式
+const DefaultTimeLayout = "2006-01-02 15:04:05.000"
+
+type segmentKind uint8
+
+const (
+	segLiteral segmentKind = iota
+	segTime
+	segLevel
+	segCaller
+	segFunc
+	segMessage
+	segFields
+)
+
+// segment 编译后的模板片段
+type segment struct {
+	kind  segmentKind
+	text  string // 字面量内容或时间格式，为空时使用配置的 TimeFormat
+	width int    // 级别宽度 / 调用路径保留段数 (0 表示文件名+上级目录)
+	full  bool   // 调用路径使用完整路径
+}
+
+// Record 模板渲染所需的日志内容
+type Record struct {
+	Time     time.Time
+	Level    Level
+	File     string // 调用文件，与 Function 都为空表示无调用信息
+	Line     int
+	Function string
+	Message  string
+	Fields   []byte // 已渲染的字段，由 AppendField 生成
+}
+
+// Formatter 编译后的控制台行格式
+//
+// 支持的占位符:
+//
+//	{time} {time:15:04:05}      时间，默认使用配置的 TimeFormat，可指定 Go 时间格式
+//	{level} {level:5}           大写级别，可指定最小宽度
+//	{caller} {caller:2}         调用位置，默认按配置的 CallerFormat 输出，数字表示保留的路径段数
+//	{caller:full}               完整路径
+//	{func}                      函数名
+//	{msg}                       日志消息
+//	{fields}                    字段 key=value
+//
+// 使用 {{ 和 }} 输出花括号，\n 表示换行。
+type Formatter struct {
+	segments []segment
+	palette  Palette
+	time     TimeEncoder // {time} 未指定格式时使用
+	caller   string      // {caller} 未指定参数时使用的 CallerFormat
+}
+
+// NewFormatter 编译模板，模板中出现未知占位符时返回错误
+func NewFormatter(template string, theme Theme) (*Formatter, error) {
+	if template == "" {
+		template = DefaultTemplate
+	}
+	template = strings.ReplaceAll(template, `\n`, "\n")
+
+	f := &Formatter{palette: theme.Palette(), time: TimeEncoder{layout: DefaultTimeLayout, loc: time.Local}}
+	var lit strings.Builder
+	flush := func() {
+		if lit.Len() > 0 {
+			f.segments = append(f.segments, segment{kind: segLiteral, text: lit.String()})
+			lit.Reset()
+		}
+	}
+
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		switch {
+		case c == '{' && i+1 < len(template) && template[i+1] == '{':
+			lit.WriteByte('{')
+			i++
+		case c == '}' && i+1 < len(template) && template[i+1] == '}':
+			lit.WriteByte('}')
+			i++
+		case c == '{':
+			end := strings.IndexByte(template[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("日志模板第 %d 个字符处的占位符未闭合", i+1)
+			}
+			seg, err := parsePlaceholder(template[i+1 : i+end])
+			if err != nil {
+				return nil, err
+			}
+			flush()
+			f.segments = append(f.segments, seg)
+			i += end
+		case c == '}':
+			return nil, fmt.Errorf("日志模板第 %d 个字符处存在多余的 }", i+1)
+		default:
+			lit.WriteByte(c)
+		}
+	}
+	flush()
+	return f, nil
+}
+
+// parsePlaceholder 解析 {name:arg} 中的内容
+func parsePlaceholder(s string) (segment, error) {
+	name, arg, hasArg := strings.Cut(s, ":")
+	switch name {
+	case "time":
+		return segment{kind: segTime, text: arg}, nil
+	case "level":
+		seg := segment{kind: segLevel}
+		if hasArg {
+			n, err := strconv.Atoi(arg)
+			if err != nil || n < 0 {
+				return segment{}, fmt.Errorf("日志模板占位符 {%s} 的宽度无效", s)
+			}
+			seg.width = n
+		}
+		return seg, nil
+	case "caller":
+		seg := segment{kind: segCaller}
+		if hasArg {
+			if arg == "full" {
+				seg.full = true
+			} else if n, err := strconv.Atoi(arg); err == nil && n > 0 {
+				seg.width = n
+			} else {
+				return segment{}, fmt.Errorf("日志模板占位符 {%s} 的参数无效", s)
+			}
+		}
+		return seg, nil
+	case "func", "msg", "message", "fields":
+		if hasArg {
+			return segment{}, fmt.Errorf("日志模板占位符 {%s} 不支持参数", s)
+		}
+		switch name {
+		case "func":
+			return segment{kind: segFunc}, nil
+		case "fields":
+			return segment{kind: segFields}, nil
+		default:
+			return segment{kind: segMessage}, nil
+		}
+	default:
+		return segment{}, fmt.Errorf("日志模板包含未知占位符 {%s}", s)
+	}
+}
+
+// Palette 返回格式使用的配色
+func (f *Formatter) Palette() Palette {
+	return f.palette
+}
+
+// Append 按模板渲染一行日志(含换行符)并追加到 buf
+func (f *Formatter) Append(buf []byte, r *Record) []byte {
+	p := f.palette
+	for i, seg := range f.segments {
+		start := len(buf)
+		switch seg.kind {
+		case segLiteral:
+			buf = append(buf, seg.text...)
+			continue
+		case segTime:
+			buf = append(buf, p.Time...)
+			if seg.text == "" {
+				buf = f.time.AppendFormat(buf, r.Time)
+			} else {
+				buf = r.Time.In(f.time.Location()).AppendFormat(buf, seg.text)
+			}
+			buf = p.appendReset(buf, p.Time)
+		case segLevel:
+			color := p.Level(r.Level)
+			buf = append(buf, color...)
+			n := len(buf)
+			buf = append(buf, r.Level.CapitalString()...)
+			for len(buf)-n < seg.width {
+				buf = append(buf, ' ')
+			}
+			buf = p.appendReset(buf, color)
+		case segCaller:
+			if r.File != "" || r.Function != "" {
+				buf = append(buf, p.Source...)
+				buf = f.appendCaller(buf, seg, r)
+				buf = p.appendReset(buf, p.Source)
+			}
+		case segFunc:
+			if r.Function != "" {
+				buf = append(buf, p.Source...)
+				buf = append(buf, shortFuncName(r.Function)...)
+				buf = p.appendReset(buf, p.Source)
+			}
+		case segMessage:
+			buf = append(buf, p.Message...)
+			buf = append(buf, r.Message...)
+			buf = p.appendReset(buf, p.Message)
+		case segFields:
+			buf = append(buf, r.Fields...)
+		}
+
+		// 占位符为空时去掉模板在它之前添加的空白，不影响消息等内容本身的空白
+		if len(buf) == start && i > 0 && f.segments[i-1].kind == segLiteral {
+			prev := f.segments[i-1].text
+			if i == len(f.segments)-1 {
+				buf = buf[:len(buf)-(len(prev)-len(strings.TrimRight(prev, " \t\n")))]
+			} else if strings.HasSuffix(prev, " ") &&
+				f.segments[i+1].kind == segLiteral && strings.HasPrefix(f.segments[i+1].text, " ") {
+				buf = buf[:len(buf)-1]
+			}
+		}
+	}
+	return append(buf, '\n')
+}
+
+// appendCaller 追加调用位置，占位符指定了参数时按参数截取文件路径，否则按 CallerFormat 输出
+func (f *Formatter) appendCaller(buf []byte, seg segment, r *Record) []byte {
+	if r.File == "" || (seg.width == 0 && !seg.full) {
+		return appendCaller(buf, f.caller, r.File, r.Line, r.Function)
+	}
+	buf = append(buf, trimCallerPath(r.File, seg.width, seg.full)...)
+	if r.Line > 0 {
+		buf = append(buf, ':')
+		buf = strconv.AppendInt(buf, int64(r.Line), 10)
+	}
+	return buf
+}
+
+// AppendField 追加一个着色的 key=value 字段，多个字段以空格分隔
+func (f *Formatter) AppendField(buf []byte, key, value string) []byte {
+	buf = f.BeginField(buf, nil, key)
+	buf = append(buf, value...)
+	return f.EndField(buf)
+}
+
+// BeginField 追加字段分隔符、着色的 prefix+key= 和值的颜色，之后由调用方直接追加值并调用 EndField，
+// 用于避免将值转换为字符串
+func (f *Formatter) BeginField(buf, prefix []byte, key string) []byte {
+	p := f.palette
+	if len(buf) > 0 {
+		buf = append(buf, ' ')
+	}
+	buf = append(buf, p.Key...)
+	buf = append(buf, prefix...)
+	buf = append(buf, key...)
+	buf = append(buf, '=')
+	buf = p.appendReset(buf, p.Key)
+	return append(buf, p.Value...)
+}
+
+// EndField 结束 BeginField 开始的字段
+func (f *Formatter) EndField(buf []byte) []byte {
+	return f.palette.appendReset(buf, f.palette.Value)
+}
+
+// trimCallerPath 保留路径的最后 n 段，n 为 0 时保留 目录/文件
+func trimCallerPath(file string, n int, full bool) string {
+	if full {
+		return file
+	}
+	if n == 0 {
+		n = 2
+	}
+	idx := len(file)
+	for ; n > 0; n-- {
+		idx = strings.LastIndexByte(file[:idx], '/')
+		if idx < 0 {
+			return file
+		}
+	}
+	return file[idx+1:]
+}
+
+// shortFuncName 去掉函数名中的包路径
+func shortFuncName(fn string) string {
+	if idx := strings.LastIndexByte(fn, '/'); idx >= 0 {
+		fn = fn[idx+1:]
+	}
+	if idx := strings.IndexByte(fn, '.'); idx >= 0 {
+		fn = fn[idx+1:]
+	}
+	return fn
+}
+
+// NewFormatter 按配置的模板、主题、时间格式、时区和调用位置格式创建控制台格式
+func (c LogConfig) NewFormatter() (*Formatter, error) {
+	timeEnc, err := c.NewTimeEncoder()
+	if err != nil {
+		return nil, err
+	}
+	f, err := NewFormatter(c.Template, c.GetTheme())
+	if err != nil {
+		return nil, err
+	}
+	f.time = timeEnc
+	f.caller = c.CallerFormat
+	return f, nil
+}
@@ -0,0 +1,153 @@
+package logmgr
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewFormatterErrors(t *testing.T) {
+	tests := []struct {
+		template string
+		err      string // 错误信息包含的内容，为空表示有效
+	}{
+		{template: ""},
+		{template: DefaultTemplate},
+		{template: "{time:15:04:05} {level:5} {caller:3} {caller:full} {func} {msg} {message} {fields}"},
+		{template: "{{literal}} {msg}"},
+		{template: "{msg}\\n{fields}"},
+		{template: "{msg", err: "未闭合"},
+		{template: "{msg}}", err: "多余的 }"},
+		{template: "msg}", err: "多余的 }"},
+		{template: "{mesage}", err: "未知占位符 {mesage}"},
+		{template: "{}", err: "未知占位符 {}"},
+		{template: "{level:x}", err: "宽度无效"},
+		{template: "{level:-1}", err: "宽度无效"},
+		{template: "{caller:0}", err: "参数无效"},
+		{template: "{caller:short}", err: "参数无效"},
+		{template: "{msg:10}", err: "不支持参数"},
+		{template: "{fields:x}", err: "不支持参数"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			_, err := NewFormatter(tt.template, DefaultTheme)
+			switch {
+			case tt.err == "" && err != nil:
+				t.Fatalf("意外的错误: %v", err)
+			case tt.err != "" && err == nil:
+				t.Fatalf("期望包含 %q 的错误", tt.err)
+			case tt.err != "" && !strings.Contains(err.Error(), tt.err):
+				t.Fatalf("错误 %q 不包含 %q", err, tt.err)
+			}
+
+			// Setup 使用 Checked 后的配置，无效模板恢复为默认模板
+			c := LogConfig{Template: tt.template}.Checked()
+			if tt.err != "" && c.Template != "" {
+				t.Fatalf("Checked() 保留了无效模板 %q", c.Template)
+			}
+			if _, err := c.NewFormatter(); err != nil {
+				t.Fatalf("Checked() 之后 NewFormatter 失败: %v", err)
+			}
+		})
+	}
+}
+
+func TestFormatterAppend(t *testing.T) {
+	r := &Record{
+		Time:     time.Date(2024, 5, 6, 7, 8, 9, 123e6, time.UTC),
+		Level:    WarnLevel,
+		File:     "/src/app/internal/db/conn.go",
+		Line:     42,
+		Function: "example.com/app/internal/db.(*Conn).Query",
+		Message:  "slow query",
+	}
+	f0, _ := NewFormatter("{msg}", Theme{})
+	fields := f0.AppendField(nil, "ms", "250")
+	fields = f0.AppendField(fields, "table", "users")
+
+	tests := []struct {
+		template   string
+		timeFormat string
+		message    string
+		fields     []byte
+		file       string
+		want       string
+	}{
+		{template: DefaultTemplate, fields: fields, file: r.File,
+			want: "[2024-05-06 07:08:09.123] [WARN] db/conn.go:42 slow query ms=250 table=users\n"},
+		{template: "{time:15:04:05} {level:5}|{caller:3} {msg}", file: r.File,
+			want: "07:08:09 WARN |internal/db/conn.go:42 slow query\n"},
+		{template: "{caller:full} {func}: {msg}", file: r.File,
+			want: "/src/app/internal/db/conn.go:42 (*Conn).Query: slow query\n"},
+		// 没有调用位置和字段时去掉多余的空格
+		{template: DefaultTemplate,
+			want: "[2024-05-06 07:08:09.123] [WARN] slow query\n"},
+		// 消息末尾的空白不被当作模板的分隔符去掉
+		{template: DefaultTemplate, message: "slow query  ",
+			want: "[2024-05-06 07:08:09.123] [WARN] slow query  \n"},
+		{template: "{msg}\t{fields}", message: "slow query\t",
+			want: "slow query\t\n"},
+		{template: "{msg} {caller} {fields}", message: "slow query ",
+			want: "slow query \n"},
+		{template: "{msg}\\n  {fields}", fields: fields,
+			want: "slow query\n  ms=250 table=users\n"},
+		{template: "{{{level}}} {msg}",
+			want: "{WARN} slow query\n"},
+		// {time} 使用配置的 TimeFormat，指定格式时不受影响
+		{template: "{time} {msg}", timeFormat: TimeFormatRFC3339,
+			want: "2024-05-06T07:08:09Z slow query\n"},
+		{template: "{time} {msg}", timeFormat: TimeFormatUnixMs,
+			want: "1714979289123 slow query\n"},
+		{template: "{time:15:04} {msg}", timeFormat: TimeFormatUnixMs,
+			want: "07:08 slow query\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			f, err := NewFormatter(tt.template, Theme{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if f.time, err = NewTimeEncoder(tt.timeFormat, "utc"); err != nil {
+				t.Fatal(err)
+			}
+			rec := *r
+			rec.File = tt.file
+			if tt.file == "" {
+				rec.Function = "" // 没有调用信息
+			}
+			if tt.message != "" {
+				rec.Message = tt.message
+			}
+			rec.Fields = tt.fields
+			if got := string(f.Append(nil, &rec)); got != tt.want {
+				t.Fatalf("得到 %q\n期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigFormatterTime(t *testing.T) {
+	ts := time.Date(2024, 5, 6, 7, 8, 9, 123e6, time.UTC)
+	tests := []struct {
+		config LogConfig
+		want   string
+	}{
+		{config: LogConfig{TimeZone: "utc"}, want: "2024-05-06 07:08:09.123"},
+		{config: LogConfig{TimeFormat: "rfc3339nano", TimeZone: "utc"}, want: "2024-05-06T07:08:09.123Z"},
+		{config: LogConfig{TimeFormat: "unix"}, want: "1714979289"},
+		{config: LogConfig{TimeFormat: "15:04:05", TimeZone: "Asia/Shanghai"}, want: "15:08:09"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			tt.config.Template = "{time}"
+			f, err := tt.config.NewFormatter()
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.palette = Palette{}
+			if got := string(f.Append(nil, &Record{Time: ts})); got != tt.want+"\n" {
+				t.Fatalf("得到 %q，期望 %q", got, tt.want)
+			}
+		})
+	}
+}
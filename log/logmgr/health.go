@@ -0,0 +1,202 @@
+package logmgr
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 持续失败时调用 OnWriteError 的最小间隔
+const writeErrorInterval = time.Second
+
+// SinkHealth 单个输出的健康状态
+type SinkHealth struct {
+	Healthy       bool      `json:"healthy"`                   // 最近一次写入成功
+	Failures      uint64    `json:"failures"`                  // 累计写入失败次数
+	LastError     string    `json:"last_error,omitempty"`      // 最近一次写入失败的错误
+	LastErrorTime time.Time `json:"last_error_time,omitempty"` // 最近一次写入失败的时间
+	Fallback      bool      `json:"fallback"`                  // 最近一次失败的写入已写入备用输出
+}
+
+// HealthStatus 日志输出的健康状态
+type HealthStatus struct {
+	Healthy bool                  `json:"healthy"` // 所有输出最近一次写入均成功
+	Sinks   map[string]SinkHealth `json:"sinks"`
+}
+
+// sinkState 记录输出的健康状态
+type sinkState struct {
+	failing  atomic.Bool // 与 health.Healthy 相反，写入成功时无需加锁即可判断
+	mu       sync.Mutex
+	health   SinkHealth
+	notified time.Time // 最近一次调用 OnWriteError 的时间
+}
+
+var (
+	healthMu   sync.Mutex
+	sinkHealth = map[string]*sinkState{}
+)
+
+// newSinkState 注册输出，重新配置时替换之前的状态
+func newSinkState(sink string) *sinkState {
+	s := &sinkState{health: SinkHealth{Healthy: true}}
+	healthMu.Lock()
+	sinkHealth[sink] = s
+	healthMu.Unlock()
+	return s
+}
+
+// Health 返回各输出的健康状态
+func Health() HealthStatus {
+	status := HealthStatus{Healthy: true, Sinks: map[string]SinkHealth{}}
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	for name, s := range sinkHealth {
+		s.mu.Lock()
+		h := s.health
+		s.mu.Unlock()
+		status.Sinks[name] = h
+		if !h.Healthy {
+			status.Healthy = false
+		}
+	}
+	return status
+}
+
+// HealthHandler 返回以 JSON 输出健康状态的 HTTP 处理器，有输出写入失败时状态码为 503
+func HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		status := Health()
+		w.Header().Set("Content-Type", "application/json")
+		if !status.Healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(status)
+	})
+}
+
+// guardedWriter 记录输出的健康状态，写入失败时通知 OnWriteError 并写入备用输出
+type guardedWriter struct {
+	sink     string
+	out      io.Writer
+	state    *sinkState
+	onError  func(sink string, err error)
+	fallback *fallbackWriter
+	counters *sinkCounters // 未开启统计时为 nil
+}
+
+func (w *guardedWriter) Write(p []byte) (int, error) {
+	n, err := w.out.Write(p)
+	if w.counters != nil {
+		w.counters.writes.Add(1)
+		w.counters.bytes.Add(uint64(n))
+		if err != nil {
+			w.counters.failures.Add(1)
+		}
+	}
+	if err == nil {
+		if w.state.failing.Load() {
+			w.state.mu.Lock()
+			w.state.failing.Store(false)
+			w.state.health.Healthy = true
+			w.state.health.Fallback = false
+			w.state.mu.Unlock()
+		}
+		return n, nil
+	}
+
+	fellBack := false
+	if w.fallback != nil {
+		_, ferr := w.fallback.Write(p)
+		fellBack = ferr == nil
+	}
+	now := time.Now()
+	w.state.mu.Lock()
+	w.state.failing.Store(true)
+	w.state.health.Healthy = false
+	w.state.health.Failures++
+	w.state.health.LastError = err.Error()
+	w.state.health.LastErrorTime = now
+	w.state.health.Fallback = fellBack
+	notify := w.onError != nil && now.Sub(w.state.notified) >= writeErrorInterval
+	if notify {
+		w.state.notified = now
+	}
+	w.state.mu.Unlock()
+	if notify {
+		w.onError(w.sink, err)
+	}
+	if w.counters != nil {
+		if fellBack {
+			w.counters.fallback.Add(1)
+		} else {
+			w.counters.dropped.Add(1)
+		}
+	}
+	if fellBack {
+		return len(p), nil
+	}
+	return n, err
+}
+
+// fallbackWriter 备用输出，文件在首次使用时打开，加密日志文件时备用文件同样加密
+type fallbackWriter struct {
+	config LogConfig
+	once   sync.Once
+	w      io.Writer
+	err    error
+}
+
+// newFallbackWriter 按 FallbackOutput 创建备用输出，未配置时返回 nil
+func (c LogConfig) newFallbackWriter() *fallbackWriter {
+	switch c.FallbackOutput {
+	case "":
+		return nil
+	case "stderr":
+		return &fallbackWriter{w: os.Stderr}
+	case "stdout", "console":
+		return &fallbackWriter{w: os.Stdout}
+	}
+	return &fallbackWriter{config: c}
+}
+
+func (f *fallbackWriter) Write(p []byte) (int, error) {
+	f.once.Do(f.open)
+	if f.err != nil {
+		return 0, f.err
+	}
+	return f.w.Write(p)
+}
+
+func (f *fallbackWriter) open() {
+	if f.w != nil {
+		// 标准输出或标准错误
+		return
+	}
+	perm, err := f.config.filePerm()
+	if err == nil {
+		err = perm.mkdir(filepath.Dir(f.config.FallbackOutput))
+	}
+	if err != nil {
+		f.err = err
+		return
+	}
+	file, err := perm.openFile(f.config.FallbackOutput, os.O_WRONLY|os.O_APPEND|os.O_CREATE)
+	if err != nil {
+		f.err = err
+		return
+	}
+	// 加密日志的备用文件同样加密，所有输出的备用记录都写入同一加密文件
+	w, err := f.config.encrypt(file)
+	if err != nil {
+		file.Close()
+		f.err = err
+		return
+	}
+	f.w = w
+}
@@ -0,0 +1,103 @@
+package logmgr
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testKey = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+
+func TestGuardedWriterFallback(t *testing.T) {
+	tests := []struct {
+		name      string
+		encrypted bool
+	}{
+		{name: "plain"},
+		{name: "encrypted", encrypted: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fallback := filepath.Join(t.TempDir(), "fallback.log")
+			c := LogConfig{FallbackOutput: fallback}
+			if tt.encrypted {
+				c.EncryptionKey = testKey
+			}
+			var notified []string
+			c.OnWriteError = func(sink string, err error) {
+				notified = append(notified, sink+": "+err.Error())
+			}
+			out := &failWriter{}
+			sink := "test-" + tt.name
+			w := c.WrapSink(sink, out)
+
+			out.fail = true
+			for _, rec := range []string{"a\n", "b\n"} {
+				if _, err := w.Write([]byte(rec)); err != nil {
+					t.Fatalf("写入备用输出后仍返回错误: %v", err)
+				}
+			}
+			if len(notified) != 1 || notified[0] != sink+": disk full" {
+				t.Fatalf("OnWriteError 调用 %q，期望每秒一次", notified)
+			}
+			if h := Health(); h.Healthy || h.Sinks[sink].Failures != 2 || !h.Sinks[sink].Fallback {
+				t.Fatalf("写入失败后的健康状态: %+v", h.Sinks[sink])
+			}
+
+			data, err := os.ReadFile(fallback)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := bytes.Contains(data, []byte("a\n")); got == tt.encrypted {
+				t.Fatalf("备用文件内容 %q，加密: %v", data, tt.encrypted)
+			}
+			if tt.encrypted {
+				key, _ := ParseEncryptionKey(testKey)
+				var plain bytes.Buffer
+				if err := DecryptLog(&plain, bytes.NewReader(data), key); err != nil {
+					t.Fatal(err)
+				}
+				data = plain.Bytes()
+			}
+			if string(data) != "a\nb\n" {
+				t.Fatalf("备用文件内容为 %q", data)
+			}
+
+			out.fail = false
+			w.Write([]byte("c\n"))
+			if h := Health().Sinks[sink]; !h.Healthy {
+				t.Fatalf("恢复写入后的健康状态: %+v", h)
+			}
+		})
+	}
+}
+
+func TestValidateFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   LogConfig
+		wantErr  bool
+		fallback string // Checked 之后的 FallbackOutput
+	}{
+		{name: "plain stderr", config: LogConfig{FallbackOutput: "stderr"}, fallback: "stderr"},
+		{name: "encrypted file", config: LogConfig{EncryptionKey: testKey, FallbackOutput: "/tmp/f.log"}, fallback: "/tmp/f.log"},
+		{name: "encrypted stderr", config: LogConfig{EncryptionKey: testKey, FallbackOutput: "stderr"}, wantErr: true},
+		{name: "encrypted stdout", config: LogConfig{EncryptionKeyFile: "key", FallbackOutput: "stdout"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v，期望错误: %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "备用输出") {
+				t.Fatalf("错误信息: %v", err)
+			}
+			if got := tt.config.Checked().FallbackOutput; got != tt.fallback {
+				t.Fatalf("Checked().FallbackOutput = %q，期望 %q", got, tt.fallback)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+// Package httplog 提供基于日志管理器的 net/http 访问日志中间件
+package httplog
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/52debug/go-box/log/logmgr"
+)
+
+// 默认的请求头
+const (
+	DefaultRequestIDHeader = "X-Request-ID"
+	DefaultDebugHeader     = "X-Debug-Log"
+)
+
+// maxRequestIDLen 沿用请求中携带的请求 ID 的最大长度
+const maxRequestIDLen = 128
+
+// Options 中间件配置
+type Options struct {
+	Logger          *slog.Logger                // 为空时使用 slog.Default()，即当前日志管理器
+	RequestIDHeader string                      // 读取和返回请求 ID 的请求头，默认 X-Request-ID；请求中的 ID 过长或包含字母、数字和 -_.: 以外的字符时重新生成
+	SkipPaths       []string                    // 不记录的路径，如 /healthz
+	Skip            func(r *http.Request) bool  // 返回 true 时不记录
+	Level           func(status int) slog.Level // 按状态码选择级别，默认 5xx 为 error，4xx 为 warn，其余为 info
+	TrustProxy      bool                        // 从 X-Forwarded-For、X-Real-IP 获取客户端 IP
+	DebugToken      string                      // 请求头 DebugHeader 等于该令牌时为请求开启 debug 日志(需开启 ContextDebug)，为空表示不支持
+	DebugHeader     string                      // 携带 debug 令牌的请求头，默认 X-Debug-Log
+}
+
+type requestIDKey struct{}
+
+// RequestID 返回中间件为请求分配的 ID
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Middleware 返回记录访问日志的中间件
+//
+// 每个请求的 context 中注入带有 request_id 的 logger，处理函数可通过 logmgr.FromContext 获取。
+func Middleware(opts Options) func(http.Handler) http.Handler {
+	header := opts.RequestIDHeader
+	if header == "" {
+		header = DefaultRequestIDHeader
+	}
+	debugHeader := opts.DebugHeader
+	if debugHeader == "" {
+		debugHeader = DefaultDebugHeader
+	}
+	level := opts.Level
+	if level == nil {
+		level = DefaultLevel
+	}
+	skipPaths := make(map[string]bool, len(opts.SkipPaths))
+	for _, p := range opts.SkipPaths {
+		skipPaths[p] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			skip := skipPaths[r.URL.Path] || (opts.Skip != nil && opts.Skip(r))
+			logger := opts.Logger
+			if logger == nil {
+				logger = slog.Default()
+			}
+
+			id := r.Header.Get(header)
+			if !validRequestID(id) {
+				id = newRequestID()
+			}
+			w.Header().Set(header, id)
+			logger = logger.With(slog.String("request_id", id))
+			ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+			if opts.DebugToken != "" {
+				token := r.Header.Get(debugHeader)
+				if subtle.ConstantTimeCompare([]byte(token), []byte(opts.DebugToken)) == 1 {
+					ctx = logmgr.WithDebug(ctx)
+				}
+			}
+			r = r.WithContext(logmgr.WithLogger(ctx, logger))
+
+			rw := &responseWriter{ResponseWriter: w}
+			next.ServeHTTP(rw, r)
+
+			if skip {
+				return
+			}
+			status := rw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			lvl := level(status)
+			h := logger.Handler()
+			// 访问日志不记录调用位置和调用栈
+			ctx = logmgr.ContextWithStacktrace(r.Context(), "")
+			if !h.Enabled(ctx, lvl) {
+				return
+			}
+			rec := slog.NewRecord(time.Now(), lvl, "HTTP 请求", 0)
+			rec.AddAttrs(
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", status),
+				slog.Int64("bytes", rw.bytes),
+				slog.Float64("latency_ms", float64(time.Since(start))/float64(time.Millisecond)),
+				slog.String("remote_ip", remoteIP(r, opts.TrustProxy)),
+			)
+			_ = h.Handle(ctx, rec)
+		})
+	}
+}
+
+// DefaultLevel 默认的级别选择: 5xx 为 error，4xx 为 warn，其余为 info
+func DefaultLevel(status int) slog.Level {
+	switch {
+	case status >= 500:
+		return slog.LevelError
+	case status >= 400:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// newRequestID 生成 16 字节的随机请求 ID
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// validRequestID 返回请求中携带的 ID 是否可以直接使用，避免写入日志和响应头的内容过长或被伪造
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// remoteIP 返回客户端 IP，trustProxy 为 true 时优先使用代理设置的请求头
+func remoteIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			if idx := strings.IndexByte(fwd, ','); idx >= 0 {
+				fwd = fwd[:idx]
+			}
+			return strings.TrimSpace(fwd)
+		}
+		if ip := r.Header.Get("X-Real-IP"); ip != "" {
+			return ip
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// responseWriter 记录状态码和写入的字节数
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush 实现 http.Flusher
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Hijack 实现 http.Hijacker，用于 WebSocket 等
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("ResponseWriter 不支持 Hijack")
+}
+
+// Unwrap 供 http.ResponseController 访问原始 ResponseWriter
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
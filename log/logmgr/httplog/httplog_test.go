@@ -0,0 +1,213 @@
+package httplog
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/52debug/go-box/log/logmgr"
+	"github.com/52debug/go-box/log/slogmgr"
+)
+
+// setup 将日志输出到捕获，返回捕获
+func setup(t *testing.T) *logmgr.Capture {
+	t.Helper()
+	c, capture := logmgr.Test()
+	c.Level = "info"
+	c.ContextDebug = true
+	slogmgr.Setup(c)
+	return capture
+}
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		handler http.HandlerFunc
+		status  int
+		bytes   int64
+		level   logmgr.Level
+		skipped bool
+	}{
+		{
+			name:    "ok",
+			path:    "/a",
+			handler: func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("hello")) },
+			status:  200,
+			bytes:   5,
+			level:   logmgr.InfoLevel,
+		},
+		{
+			name:    "no body",
+			path:    "/a",
+			handler: func(w http.ResponseWriter, r *http.Request) {},
+			status:  200,
+			level:   logmgr.InfoLevel,
+		},
+		{
+			name: "not found",
+			path: "/a",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte("nf"))
+			},
+			status: 404,
+			bytes:  2,
+			level:  logmgr.WarnLevel,
+		},
+		{
+			name: "server error",
+			path: "/a",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.WriteHeader(http.StatusOK) // 只记录第一次设置的状态码
+			},
+			status: 500,
+			level:  logmgr.ErrorLevel,
+		},
+		{
+			name:    "skip path",
+			path:    "/healthz",
+			handler: func(w http.ResponseWriter, r *http.Request) {},
+			skipped: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			capture := setup(t)
+			h := Middleware(Options{SkipPaths: []string{"/healthz"}})(tt.handler)
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, tt.path, nil))
+
+			entries := capture.Entries()
+			if tt.skipped {
+				if len(entries) != 0 {
+					t.Fatalf("记录了 %d 条日志", len(entries))
+				}
+				return
+			}
+			if len(entries) != 1 {
+				t.Fatalf("记录了 %d 条日志", len(entries))
+			}
+			e := entries[0]
+			if e.Level != tt.level {
+				t.Fatalf("级别 %v，期望 %v", e.Level, tt.level)
+			}
+			if e.Fields["method"] != "POST" || e.Fields["path"] != tt.path {
+				t.Fatalf("字段 %v", e.Fields)
+			}
+			if got := e.Fields["status"]; got != json.Number(strconv.Itoa(tt.status)) {
+				t.Fatalf("status = %v，期望 %d", got, tt.status)
+			}
+			if got := e.Fields["bytes"]; got != json.Number(strconv.FormatInt(tt.bytes, 10)) {
+				t.Fatalf("bytes = %v，期望 %d", got, tt.bytes)
+			}
+			ms, ok := e.Fields["latency_ms"].(json.Number)
+			if !ok {
+				t.Fatalf("latency_ms = %v", e.Fields["latency_ms"])
+			}
+			if f, err := ms.Float64(); err != nil || f < 0 {
+				t.Fatalf("latency_ms = %v", ms)
+			}
+			if e.Caller != "" || e.Stack != "" {
+				t.Fatalf("记录了调用位置 %q 或调用栈 %q", e.Caller, e.Stack)
+			}
+		})
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	long := strings.Repeat("a", maxRequestIDLen)
+	tests := []struct {
+		name string
+		id   string // 请求中携带的 ID
+		keep bool   // 沿用请求中的 ID
+	}{
+		{name: "empty"},
+		{name: "valid", id: "req-1_a.b:c", keep: true},
+		{name: "max length", id: long, keep: true},
+		{name: "too long", id: long + "a"},
+		{name: "space", id: "a b"},
+		{name: "newline", id: "a\nb"},
+		{name: "quote", id: `a"b`},
+		{name: "unicode", id: "请求"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			capture := setup(t)
+			var ctxID, logID string
+			h := Middleware(Options{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctxID = RequestID(r.Context())
+				logmgr.FromContext(r.Context()).Info("handler")
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.id != "" {
+				req.Header.Set(DefaultRequestIDHeader, tt.id)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			respID := rec.Header().Get(DefaultRequestIDHeader)
+			if tt.keep {
+				if respID != tt.id {
+					t.Fatalf("响应的请求 ID %q，期望 %q", respID, tt.id)
+				}
+			} else if len(respID) != 32 || respID == tt.id {
+				t.Fatalf("没有生成新的请求 ID: %q", respID)
+			}
+			if ctxID != respID {
+				t.Fatalf("context 中的请求 ID %q，响应 %q", ctxID, respID)
+			}
+			// 处理函数的日志和访问日志都带有请求 ID
+			entries := capture.Entries()
+			if len(entries) != 2 {
+				t.Fatalf("记录了 %d 条日志", len(entries))
+			}
+			for _, e := range entries {
+				if logID, _ = e.Fields["request_id"].(string); logID != respID {
+					t.Fatalf("%q 的请求 ID %q，期望 %q", e.Message, logID, respID)
+				}
+			}
+		})
+	}
+}
+
+func TestDebugHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string // 中间件配置的令牌
+		header string // 请求携带的令牌
+		debug  bool
+	}{
+		{name: "match", token: "secret", header: "secret", debug: true},
+		{name: "mismatch", token: "secret", header: "secrets"},
+		{name: "missing", token: "secret"},
+		{name: "disabled", header: "secret"},
+		{name: "disabled empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			capture := setup(t)
+			h := Middleware(Options{DebugToken: tt.token})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				logmgr.FromContext(r.Context()).DebugContext(r.Context(), "debug")
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(DefaultDebugHeader, tt.header)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			var debug bool
+			for _, e := range capture.Entries() {
+				if e.Message == "debug" {
+					debug = true
+				}
+			}
+			if debug != tt.debug {
+				t.Fatalf("输出 debug 日志: %v，期望 %v", debug, tt.debug)
+			}
+		})
+	}
+}
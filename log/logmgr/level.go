@@ -0,0 +1,182 @@
+package logmgr
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// Level 日志级别，零值为 InfoLevel
+type Level int8
+
+const (
+	TraceLevel Level = iota - 2
+	DebugLevel
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+	PanicLevel // 记录后 panic
+	FatalLevel // 记录后退出进程
+)
+
+// slog 没有定义的级别，与 slog 的间隔保持一致
+const (
+	SlogLevelTrace = slog.Level(-8)
+	SlogLevelPanic = slog.Level(12)
+	SlogLevelFatal = slog.Level(16)
+)
+
+var levelNames = map[string]Level{
+	"trace":    TraceLevel,
+	"debug":    DebugLevel,
+	"info":     InfoLevel,
+	"warn":     WarnLevel,
+	"warning":  WarnLevel,
+	"error":    ErrorLevel,
+	"err":      ErrorLevel,
+	"panic":    PanicLevel,
+	"dpanic":   PanicLevel,
+	"fatal":    FatalLevel,
+	"critical": FatalLevel,
+	"alert":    FatalLevel,
+}
+
+// ParseLevel 解析级别名称，不区分大小写，支持 warning、err 等别名，空串返回 InfoLevel
+func ParseLevel(s string) (Level, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return InfoLevel, nil
+	}
+	if l, ok := levelNames[s]; ok {
+		return l, nil
+	}
+	return InfoLevel, fmt.Errorf("未知的日志级别: %q", s)
+}
+
+// String 返回小写级别名
+func (l Level) String() string {
+	switch l {
+	case TraceLevel:
+		return "trace"
+	case DebugLevel:
+		return "debug"
+	case InfoLevel:
+		return "info"
+	case WarnLevel:
+		return "warn"
+	case ErrorLevel:
+		return "error"
+	case PanicLevel:
+		return "panic"
+	case FatalLevel:
+		return "fatal"
+	default:
+		return fmt.Sprintf("level(%d)", int8(l))
+	}
+}
+
+// CapitalString 返回大写级别名
+func (l Level) CapitalString() string {
+	switch l {
+	case TraceLevel:
+		return "TRACE"
+	case DebugLevel:
+		return "DEBUG"
+	case InfoLevel:
+		return "INFO"
+	case WarnLevel:
+		return "WARN"
+	case ErrorLevel:
+		return "ERROR"
+	case PanicLevel:
+		return "PANIC"
+	case FatalLevel:
+		return "FATAL"
+	default:
+		return strings.ToUpper(l.String())
+	}
+}
+
+// MarshalText 实现 encoding.TextMarshaler
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText 实现 encoding.TextUnmarshaler
+func (l *Level) UnmarshalText(text []byte) error {
+	parsed, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = parsed
+	return nil
+}
+
+// SlogLevel 转换为 slog 级别
+func (l Level) SlogLevel() slog.Level {
+	switch l {
+	case TraceLevel:
+		return SlogLevelTrace
+	case DebugLevel:
+		return slog.LevelDebug
+	case WarnLevel:
+		return slog.LevelWarn
+	case ErrorLevel:
+		return slog.LevelError
+	case PanicLevel:
+		return SlogLevelPanic
+	case FatalLevel:
+		return SlogLevelFatal
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// LevelFromSlog 将 slog 级别转换为不高于它的最近级别
+func LevelFromSlog(l slog.Level) Level {
+	switch {
+	case l >= SlogLevelFatal:
+		return FatalLevel
+	case l >= SlogLevelPanic:
+		return PanicLevel
+	case l >= slog.LevelError:
+		return ErrorLevel
+	case l >= slog.LevelWarn:
+		return WarnLevel
+	case l >= slog.LevelInfo:
+		return InfoLevel
+	case l >= slog.LevelDebug:
+		return DebugLevel
+	default:
+		return TraceLevel
+	}
+}
+
+// SlogLevelName 返回 slog 级别的小写名称，与 Level 对应的级别使用 Level 名称，其他保持 slog 的表示(如 info+2)
+func SlogLevelName(l slog.Level) string {
+	if lv := LevelFromSlog(l); lv.SlogLevel() == l {
+		return lv.String()
+	}
+	return strings.ToLower(l.String())
+}
+
+// GetLevel 返回配置的日志级别，无法解析时返回 InfoLevel(Setup 时由 Checked 输出错误，见 Validate)
+func (c LogConfig) GetLevel() Level {
+	l, _ := ParseLevel(c.Level)
+	return l
+}
+
+// GetStacktraceLevel 返回记录堆栈的最低级别，默认 ErrorLevel(无法解析时同样)，ok 为 false 表示不记录
+func (c LogConfig) GetStacktraceLevel() (level Level, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(c.StacktraceLevel)) {
+	case "none", "off":
+		return 0, false
+	case "":
+		return ErrorLevel, true
+	}
+	l, err := ParseLevel(c.StacktraceLevel)
+	if err != nil {
+		return ErrorLevel, true
+	}
+	return l, true
+}
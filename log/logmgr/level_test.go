@@ -0,0 +1,115 @@
+package logmgr
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Level
+		wantErr bool
+	}{
+		{in: "", want: InfoLevel},
+		{in: "trace", want: TraceLevel},
+		{in: "DEBUG", want: DebugLevel},
+		{in: " Info ", want: InfoLevel},
+		{in: "warn", want: WarnLevel},
+		{in: "Warning", want: WarnLevel},
+		{in: "err", want: ErrorLevel},
+		{in: "error", want: ErrorLevel},
+		{in: "dpanic", want: PanicLevel},
+		{in: "panic", want: PanicLevel},
+		{in: "fatal", want: FatalLevel},
+		{in: "critical", want: FatalLevel},
+		{in: "warnig", want: InfoLevel, wantErr: true},
+		{in: "5", want: InfoLevel, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseLevel(tt.in)
+			if got != tt.want || (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLevel(%q) = %v, %v，期望 %v，错误: %v", tt.in, got, err, tt.want, tt.wantErr)
+			}
+			var l Level
+			if err := l.UnmarshalText([]byte(tt.in)); (err != nil) != tt.wantErr || (err == nil && l != tt.want) {
+				t.Fatalf("UnmarshalText(%q) = %v, %v", tt.in, l, err)
+			}
+		})
+	}
+}
+
+func TestLevelSlog(t *testing.T) {
+	tests := []struct {
+		level Level
+		slog  slog.Level
+		name  string
+	}{
+		{TraceLevel, SlogLevelTrace, "trace"},
+		{DebugLevel, slog.LevelDebug, "debug"},
+		{InfoLevel, slog.LevelInfo, "info"},
+		{WarnLevel, slog.LevelWarn, "warn"},
+		{ErrorLevel, slog.LevelError, "error"},
+		{PanicLevel, SlogLevelPanic, "panic"},
+		{FatalLevel, SlogLevelFatal, "fatal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.level.SlogLevel(); got != tt.slog {
+				t.Fatalf("SlogLevel() = %v，期望 %v", got, tt.slog)
+			}
+			if got := LevelFromSlog(tt.slog); got != tt.level {
+				t.Fatalf("LevelFromSlog(%v) = %v", tt.slog, got)
+			}
+			if got := tt.level.String(); got != tt.name {
+				t.Fatalf("String() = %q", got)
+			}
+			if got := SlogLevelName(tt.slog); got != tt.name {
+				t.Fatalf("SlogLevelName(%v) = %q", tt.slog, got)
+			}
+		})
+	}
+
+	// 介于两个级别之间的 slog 级别向下取整，名称保持 slog 的表示
+	if got := LevelFromSlog(slog.LevelInfo + 2); got != InfoLevel {
+		t.Fatalf("LevelFromSlog(info+2) = %v", got)
+	}
+	if got := SlogLevelName(slog.LevelInfo + 2); got != "info+2" {
+		t.Fatalf("SlogLevelName(info+2) = %q", got)
+	}
+}
+
+func TestValidateLevel(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     LogConfig
+		wantErr    bool
+		level      Level
+		stackLevel Level
+		stack      bool
+	}{
+		{name: "default", config: LogConfig{}, level: InfoLevel, stackLevel: ErrorLevel, stack: true},
+		{name: "alias", config: LogConfig{Level: "WARNING", StacktraceLevel: "warn"}, level: WarnLevel, stackLevel: WarnLevel, stack: true},
+		{name: "no stack", config: LogConfig{StacktraceLevel: "none"}, level: InfoLevel, stack: false},
+		{name: "typo", config: LogConfig{Level: "warnig"}, wantErr: true, level: InfoLevel, stackLevel: ErrorLevel, stack: true},
+		{name: "stack typo", config: LogConfig{StacktraceLevel: "eror"}, wantErr: true, level: InfoLevel, stackLevel: ErrorLevel, stack: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v，期望错误: %v", err, tt.wantErr)
+			}
+			c := tt.config.Checked()
+			if c.Validate() != nil {
+				t.Fatalf("Checked() 之后仍有错误: %v", c.Validate())
+			}
+			if got := c.GetLevel(); got != tt.level {
+				t.Fatalf("GetLevel() = %v，期望 %v", got, tt.level)
+			}
+			if got, ok := c.GetStacktraceLevel(); ok != tt.stack || (ok && got != tt.stackLevel) {
+				t.Fatalf("GetStacktraceLevel() = %v, %v", got, ok)
+			}
+		})
+	}
+}
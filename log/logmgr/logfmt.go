@@ -0,0 +1,55 @@
+package logmgr
+
+import (
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 输出编码
+const (
+	EncodingText   = "text"   // 按模板输出的彩色文本
+	EncodingJSON   = "json"   // 每行一个 JSON 对象
+	EncodingLogfmt = "logfmt" // 每行 key=value 序列
+)
+
+// AppendLogfmt 追加一个 logfmt 键值对，多个键值对以空格分隔
+func AppendLogfmt(buf []byte, key, value string) []byte {
+	if len(buf) > 0 && buf[len(buf)-1] != '\n' {
+		buf = append(buf, ' ')
+	}
+	buf = appendLogfmtKey(buf, key)
+	buf = append(buf, '=')
+	if needsQuote(value) {
+		return strconv.AppendQuote(buf, value)
+	}
+	return append(buf, value...)
+}
+
+// appendLogfmtKey 追加键名，空白、引号、等号及控制字符替换为下划线
+func appendLogfmtKey(buf []byte, key string) []byte {
+	if key == "" {
+		return append(buf, '_')
+	}
+	for _, r := range key {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			buf = append(buf, '_')
+			continue
+		}
+		buf = utf8.AppendRune(buf, r)
+	}
+	return buf
+}
+
+// needsQuote 值为空或包含空白、引号、等号、反斜杠、不可打印字符时需要加引号
+func needsQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == utf8.RuneError || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
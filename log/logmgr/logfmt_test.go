@@ -0,0 +1,48 @@
+package logmgr
+
+import "testing"
+
+func TestAppendLogfmt(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		want  string
+	}{
+		{name: "plain", key: "k", value: "v", want: `k=v`},
+		{name: "empty value", key: "k", value: "", want: `k=""`},
+		{name: "space", key: "msg", value: "hello world", want: `msg="hello world"`},
+		{name: "equals", key: "q", value: "a=b", want: `q="a=b"`},
+		{name: "quote", key: "q", value: `say "hi"`, want: `q="say \"hi\""`},
+		{name: "backslash", key: "path", value: `C:\tmp`, want: `path="C:\\tmp"`},
+		{name: "newline", key: "err", value: "line1\nline2", want: `err="line1\nline2"`},
+		{name: "tab", key: "k", value: "a\tb", want: `k="a\tb"`},
+		{name: "control", key: "k", value: "a\x1b[31m", want: `k="a\x1b[31m"`},
+		{name: "invalid utf8", key: "k", value: "a\xffb", want: `k="a\xffb"`},
+		{name: "unicode space", key: "k", value: "a\u00a0b", want: `k="a\u00a0b"`},
+		{name: "unicode", key: "城市", value: "北京", want: `城市=北京`},
+		{name: "empty key", key: "", value: "v", want: `_=v`},
+		{name: "key with space", key: "a b", value: "v", want: `a_b=v`},
+		{name: "key with equals", key: "a=b", value: "v", want: `a_b=v`},
+		{name: "key with quote", key: `a"b`, value: "v", want: `a_b=v`},
+		{name: "key with newline", key: "a\nb", value: "v", want: `a_b=v`},
+		{name: "key with invalid utf8", key: "a\xffb", value: "v", want: `a_b=v`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(AppendLogfmt(nil, tt.key, tt.value)); got != tt.want {
+				t.Fatalf("AppendLogfmt(%q, %q) = %s，期望 %s", tt.key, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppendLogfmtSeparator(t *testing.T) {
+	buf := AppendLogfmt(nil, "a", "1")
+	buf = AppendLogfmt(buf, "b", "2")
+	buf = append(buf, '\n')
+	buf = AppendLogfmt(buf, "c", "3")
+	if got, want := string(buf), "a=1 b=2\nc=3"; got != want {
+		t.Fatalf("得到 %q，期望 %q", got, want)
+	}
+}
@@ -0,0 +1,243 @@
+// Package logquery 在日志文件及其 lumberjack 备份中按条件查询 JSON 日志
+package logquery
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/52debug/go-box/log/logmgr"
+)
+
+// Query 查询条件，零值匹配所有记录
+type Query struct {
+	Since   time.Time      // 不早于该时间，零值表示不限
+	Until   time.Time      // 早于该时间，零值表示不限
+	Level   *logmgr.Level  // 最低级别，nil 表示不限
+	Message *regexp.Regexp // 消息匹配的正则
+	Fields  []Predicate    // 字段条件，需全部满足
+}
+
+// Match 匹配的记录
+type Match struct {
+	*logmgr.Entry
+	File string // 所在文件
+	Line int    // 文件中的行号，从 1 开始
+	Raw  []byte // 原始 JSON 行(不含换行)
+}
+
+// Run 按时间顺序读取 path 及其备份(自动解压 .gz 和 .zst)，对每条匹配的记录调用 fn，fn 返回 false 时停止
+//
+// parser 为 nil 时使用默认配置的解析器。无法解析为 JSON 的行被忽略，轮转时间早于 Since 的备份文件不会被读取。
+func Run(path string, q Query, parser *logmgr.EntryParser, fn func(*Match) bool) error {
+	if parser == nil {
+		var err error
+		if parser, err = (logmgr.LogConfig{}).NewEntryParser(); err != nil {
+			return err
+		}
+	}
+	files, err := logmgr.LogFiles(path)
+	if err != nil {
+		return err
+	}
+	for i, file := range files {
+		if t, ok := logmgr.BackupTime(path, file); ok && !q.Since.IsZero() && t.Before(q.Since) {
+			continue
+		}
+		if i > 0 && !q.Until.IsZero() {
+			// 上一个文件的轮转时间即本文件最早记录的时间，已晚于 Until 时无需继续
+			if t, ok := logmgr.BackupTime(path, files[i-1]); ok && !t.Before(q.Until) {
+				return nil
+			}
+		}
+		f, err := logmgr.OpenLogFile(file)
+		if err != nil {
+			return err
+		}
+		more, err := scan(f, file, q, parser, fn)
+		f.Close()
+		if err != nil || !more {
+			return err
+		}
+	}
+	return nil
+}
+
+// scan 查询单个文件，返回 false 表示 fn 要求停止
+func scan(r io.Reader, file string, q Query, parser *logmgr.EntryParser, fn func(*Match) bool) (bool, error) {
+	br := bufio.NewReader(r)
+	for lineNo := 1; ; lineNo++ {
+		line, err := br.ReadBytes('\n')
+		if line = bytes.TrimRight(line, "\r\n"); len(line) > 0 {
+			if e, perr := parser.Parse(line); perr == nil && q.Match(e) {
+				if !fn(&Match{Entry: e, File: file, Line: lineNo, Raw: line}) {
+					return false, nil
+				}
+			}
+		}
+		if err == io.EOF {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+}
+
+// Match 记录是否满足所有条件
+func (q *Query) Match(e *logmgr.Entry) bool {
+	if !q.Since.IsZero() && e.Time.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !e.Time.Before(q.Until) {
+		return false
+	}
+	if q.Level != nil && e.Level < *q.Level {
+		return false
+	}
+	if q.Message != nil && !q.Message.MatchString(e.Message) {
+		return false
+	}
+	for _, p := range q.Fields {
+		if !p.Match(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// Predicate 字段条件
+type Predicate struct {
+	Key   string         // 字段名，嵌套字段使用 . 分隔，如 user.id
+	Op    string         // =, !=, >, >=, <, <=, ~(正则)
+	Value string         // 比较值，两侧都是数字时按数值比较
+	re    *regexp.Regexp // Op 为 ~ 时编译的正则
+}
+
+// 支持的运算符，出现位置相同时使用较长的
+var predicateOps = []string{"!=", ">=", "<=", "=", ">", "<", "~"}
+
+// ParsePredicate 解析 key=value 形式的字段条件，如 user_id=42、latency>=100、path~^/api
+func ParsePredicate(s string) (Predicate, error) {
+	idx, op := -1, ""
+	for _, o := range predicateOps {
+		if i := strings.Index(s, o); i > 0 && (idx < 0 || i < idx || (i == idx && len(o) > len(op))) {
+			idx, op = i, o
+		}
+	}
+	if idx < 0 {
+		return Predicate{}, fmt.Errorf("无效的字段条件 %q，应为 key=value 等形式", s)
+	}
+	p := Predicate{Key: strings.TrimSpace(s[:idx]), Op: op, Value: strings.TrimSpace(s[idx+len(op):])}
+	if op == "~" {
+		re, err := regexp.Compile(p.Value)
+		if err != nil {
+			return Predicate{}, fmt.Errorf("字段条件 %q 的正则无效: %w", s, err)
+		}
+		p.re = re
+	}
+	return p, nil
+}
+
+// Match 记录是否满足条件，字段不存在时只满足 !=
+func (p *Predicate) Match(e *logmgr.Entry) bool {
+	v, ok := lookup(e, p.Key)
+	if !ok {
+		return p.Op == "!="
+	}
+	s := logmgr.FieldString(v)
+	if p.Op == "~" {
+		return p.re.MatchString(s)
+	}
+	c := compare(s, p.Value)
+	switch p.Op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	}
+	return false
+}
+
+// lookup 查找字段，内置字段 msg、level、caller 也可作为条件
+func lookup(e *logmgr.Entry, key string) (interface{}, bool) {
+	switch key {
+	case "msg", "message":
+		return e.Message, true
+	case "level":
+		return e.Level.String(), true
+	case "caller":
+		return e.Caller, e.Caller != ""
+	}
+	if v, ok := e.Fields[key]; ok {
+		return v, true
+	}
+	// slog 的分组输出为嵌套对象
+	var cur interface{} = e.Fields
+	for _, part := range strings.Split(key, ".") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// compare 比较两个值，都能解析为数字时按数值比较
+func compare(a, b string) int {
+	x, err1 := strconv.ParseFloat(a, 64)
+	y, err2 := strconv.ParseFloat(b, 64)
+	if err1 == nil && err2 == nil {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
+
+// ParseTime 解析查询时间: RFC 3339、"2006-01-02 15:04:05"、"2006-01-02"，
+// 或当天的 "15:04"、"15:04:05"，以及相对时间如 "-15m"(相对 now)
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	if strings.HasPrefix(s, "-") {
+		d, err := time.ParseDuration(s[1:])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("无效的时间 %q: %w", s, err)
+		}
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{logmgr.DefaultTimeLayout, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			y, m, d := now.Date()
+			return time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), 0, now.Location()), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无效的时间 %q", s)
+}
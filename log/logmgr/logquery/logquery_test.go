@@ -0,0 +1,237 @@
+package logquery
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/52debug/go-box/log/logmgr"
+)
+
+func TestParsePredicate(t *testing.T) {
+	tests := []struct {
+		in      string
+		key     string
+		op      string
+		value   string
+		wantErr bool
+	}{
+		{in: "user_id=42", key: "user_id", op: "=", value: "42"},
+		{in: "a!=b", key: "a", op: "!=", value: "b"},
+		{in: "x>=1", key: "x", op: ">=", value: "1"},
+		{in: "x<=1", key: "x", op: "<=", value: "1"},
+		{in: "x>1", key: "x", op: ">", value: "1"},
+		{in: "x<1", key: "x", op: "<", value: "1"},
+		// 最先出现的运算符生效，值中可以包含其他运算符
+		{in: "path~^/a=b", key: "path", op: "~", value: "^/a=b"},
+		{in: "q=a>=b", key: "q", op: "=", value: "a>=b"},
+		{in: " user.id = 7 ", key: "user.id", op: "=", value: "7"},
+		{in: "a=", key: "a", op: "=", value: ""},
+		{in: "=a", wantErr: true},
+		{in: "noop", wantErr: true},
+		{in: "path~[", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			p, err := ParsePredicate(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePredicate() 错误 %v，期望错误: %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if p.Key != tt.key || p.Op != tt.op || p.Value != tt.value {
+				t.Fatalf("ParsePredicate() = %q %q %q，期望 %q %q %q", p.Key, p.Op, p.Value, tt.key, tt.op, tt.value)
+			}
+		})
+	}
+}
+
+func TestPredicateMatch(t *testing.T) {
+	e := &logmgr.Entry{
+		Level:   logmgr.WarnLevel,
+		Message: "slow query",
+		Fields: map[string]interface{}{
+			"latency": "120",
+			"path":    "/api/users",
+			"user":    map[string]interface{}{"id": "42"},
+		},
+	}
+	tests := []struct {
+		pred string
+		want bool
+	}{
+		{"latency>100", true},
+		{"latency>=120", true},
+		{"latency<99", false},
+		{"latency=120.0", true}, // 按数值比较
+		{"path~^/api/", true},
+		{"path~^/admin", false},
+		{"path>/a", true}, // 按字符串比较
+		{"user.id=42", true},
+		{"msg~slow", true},
+		{"level=warn", true},
+		{"missing=1", false},
+		{"missing!=1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pred, func(t *testing.T) {
+			p, err := ParsePredicate(tt.pred)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := p.Match(e); got != tt.want {
+				t.Fatalf("Match() = %v，期望 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	zone := time.FixedZone("UTC+8", 8*3600)
+	now := time.Date(2024, 3, 5, 12, 30, 0, 0, zone)
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		// 只有时间时使用 now 所在时区的当天
+		{in: "10:15", want: time.Date(2024, 3, 5, 10, 15, 0, 0, zone)},
+		{in: "10:15:30", want: time.Date(2024, 3, 5, 10, 15, 30, 0, zone)},
+		{in: "2024-03-01", want: time.Date(2024, 3, 1, 0, 0, 0, 0, zone)},
+		{in: "2024-03-01 08:00", want: time.Date(2024, 3, 1, 8, 0, 0, 0, zone)},
+		{in: "2024-03-01 08:00:05", want: time.Date(2024, 3, 1, 8, 0, 5, 0, zone)},
+		// RFC 3339 使用自身的时区
+		{in: "2024-03-01T08:00:00Z", want: time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)},
+		{in: "2024-03-01T08:00:00.5+02:00", want: time.Date(2024, 3, 1, 6, 0, 0, 5e8, time.UTC)},
+		{in: "-15m", want: now.Add(-15 * time.Minute)},
+		{in: "-1h30m", want: now.Add(-90 * time.Minute)},
+		{in: "-abc", wantErr: true},
+		{in: "yesterday", wantErr: true},
+		{in: "25:00", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseTime(tt.in, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTime() 错误 %v，期望错误: %v", err, tt.wantErr)
+			}
+			if err == nil && !got.Equal(tt.want) {
+				t.Fatalf("ParseTime() = %v，期望 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	line := func(ts, level, msg, extra string) string {
+		return `{"time":"2024-03-05T` + ts + `Z","level":"` + level + `","msg":"` + msg + `"` + extra + "}\n"
+	}
+	// 备份文件名中的时间为轮转时间，即文件中最后一条记录之后；损坏的压缩备份一旦读取就会返回错误
+	writeFile(t, filepath.Join(dir, "app-2024-03-05T10-00-00.000.log.gz"), "损坏的备份")
+	writeFile(t, filepath.Join(dir, "app-2024-03-05T11-00-00.000.log"),
+		line("10:30:00", "info", "a1", "")+"not json\n"+line("10:50:00", "error", "a2", `,"user_id":42`))
+	writeGzip(t, filepath.Join(dir, "app-2024-03-05T12-00-00.000.log.gz"), line("11:30:00", "warn", "c1", `,"user_id":42`))
+	writeFile(t, filepath.Join(dir, "app-2024-03-05T13-00-00.000.log.gz"), "损坏的备份")
+	writeFile(t, path, line("13:30:00", "info", "d1", ""))
+
+	at := func(hm string) time.Time {
+		t, _ := time.Parse(time.RFC3339, "2024-03-05T"+hm+":00Z")
+		return t
+	}
+	errorLevel := logmgr.ErrorLevel
+	userID, _ := ParsePredicate("user_id=42")
+	tests := []struct {
+		name    string
+		q       Query
+		limit   int      // 大于 0 时匹配该条数后停止
+		want    []string // 消息@文件名:行号
+		wantErr bool
+	}{
+		{
+			// 轮转时间早于 Since 的备份和前一个备份轮转时间不早于 Until 的文件都不读取
+			name: "prune",
+			q:    Query{Since: at("10:15"), Until: at("12:00")},
+			want: []string{
+				"a1@app-2024-03-05T11-00-00.000.log:1",
+				"a2@app-2024-03-05T11-00-00.000.log:3",
+				"c1@app-2024-03-05T12-00-00.000.log.gz:1",
+			},
+		},
+		{
+			name: "since inside file",
+			q:    Query{Since: at("10:40"), Until: at("11:00")},
+			want: []string{"a2@app-2024-03-05T11-00-00.000.log:3"},
+		},
+		{
+			name: "current file only",
+			q:    Query{Since: at("13:15")},
+			want: []string{"d1@app.log:1"},
+		},
+		{
+			name: "level and field",
+			q:    Query{Since: at("10:15"), Until: at("12:00"), Level: &errorLevel, Fields: []Predicate{userID}},
+			want: []string{"a2@app-2024-03-05T11-00-00.000.log:3"},
+		},
+		{
+			name:  "stop",
+			q:     Query{Since: at("10:15"), Until: at("12:00")},
+			limit: 1,
+			want:  []string{"a1@app-2024-03-05T11-00-00.000.log:1"},
+		},
+		{
+			name:    "no since reads all backups",
+			q:       Query{Until: at("10:40")},
+			wantErr: true,
+		},
+		{
+			name:    "no until reads all backups",
+			q:       Query{Since: at("10:15")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			err := Run(path, tt.q, nil, func(m *Match) bool {
+				got = append(got, m.Message+"@"+filepath.Base(m.File)+":"+strconv.Itoa(m.Line))
+				return tt.limit == 0 || len(got) < tt.limit
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() 错误 %v，期望错误: %v", err, tt.wantErr)
+			}
+			if err == nil && strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("匹配 %q，期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeGzip(t *testing.T, path, content string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := gzip.NewWriter(f)
+	if _, err := zw.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
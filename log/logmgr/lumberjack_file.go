@@ -0,0 +1,157 @@
+package logmgr
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// 检查日志文件是否被删除或移走的间隔
+const reopenCheckInterval = time.Second
+
+// lumberjackFile 使用 lumberjack 轮转的日志文件，按配置设置权限和所有者并压缩备份
+//
+// lumberjack 打开已有文件，轮转时新文件沿用原文件的权限和所有者，因此先按配置创建文件。
+// 每隔 reopenCheckInterval 检查 path 是否仍指向打开的文件，文件被删除或移走(如被 logrotate 重命名)时重新创建，
+// 已轮转时重新设置新文件的权限。压缩方式不是默认级别的 gzip 时由本类型压缩备份并按 MaxBackups 和 MaxAge 清理。
+type lumberjackFile struct {
+	mu      sync.Mutex
+	config  LogConfig
+	perm    filePerm
+	codec   codec
+	lj      *lumberjack.Logger
+	info    os.FileInfo // 最近一次检查时 path 指向的文件
+	checked time.Time
+	closed  bool
+	mill    chan struct{} // 非 nil 时由本类型压缩和清理备份
+}
+
+func newLumberjackFile(c LogConfig) (*lumberjackFile, error) {
+	perm, err := c.filePerm()
+	if err != nil {
+		return nil, err
+	}
+	cd, err := c.codec()
+	if err != nil {
+		return nil, err
+	}
+	f := &lumberjackFile{
+		config: c,
+		perm:   perm,
+		codec:  cd,
+		lj:     &lumberjack.Logger{Filename: c.FilePath, MaxSize: c.MaxSize},
+	}
+	if c.Compress && !cd.builtin() {
+		f.mill = make(chan struct{}, 1)
+		go f.millRun()
+		f.mill <- struct{}{}
+	} else {
+		f.lj.MaxBackups = c.MaxBackups
+		f.lj.MaxAge = c.MaxAge
+		f.lj.Compress = c.Compress
+	}
+	// 立即创建文件，使权限和所有者在 RedirectStderr 等打开文件之前生效
+	if err := f.create(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *lumberjackFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	if time.Since(f.checked) >= reopenCheckInterval {
+		f.check()
+	}
+	n, err := f.lj.Write(p)
+	if err != nil {
+		// 下次写入时检查文件是否仍然有效
+		f.checked = time.Time{}
+	}
+	return n, err
+}
+
+// locked 在持有互斥锁时调用 fn，期间不会发生轮转
+func (f *lumberjackFile) locked(fn func() error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return fn()
+}
+
+// create 按配置的权限和所有者创建(或打开已有的)日志文件
+func (f *lumberjackFile) create() error {
+	if err := f.perm.mkdir(filepath.Dir(f.config.FilePath)); err != nil {
+		return err
+	}
+	file, err := f.perm.openFile(f.config.FilePath, os.O_WRONLY|os.O_APPEND|os.O_CREATE)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	f.info = info
+	f.checked = time.Now()
+	return nil
+}
+
+// check 在 path 不再指向上次检查的文件时(已轮转，或被删除、移走)按配置重新创建文件，
+// 并关闭 lumberjack 打开的文件，下次写入时由 lumberjack 重新打开
+func (f *lumberjackFile) check() {
+	f.checked = time.Now()
+	info, err := os.Stat(f.config.FilePath)
+	if err == nil && os.SameFile(info, f.info) {
+		return
+	}
+	f.create()
+	f.lj.Close()
+	if f.mill != nil {
+		select {
+		case f.mill <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// millRun 压缩未压缩的备份并清理旧备份，直到 Close
+func (f *lumberjackFile) millRun() {
+	for range f.mill {
+		backups, err := backupFiles(f.config.FilePath)
+		if err != nil {
+			continue
+		}
+		for _, b := range backups {
+			if trimCompressExt(b.path) != b.path {
+				continue
+			}
+			if err := compressFile(b.path, f.codec, f.perm, nil); err != nil {
+				fmt.Fprintf(os.Stderr, "压缩日志文件失败: %v\n", err)
+			}
+		}
+		removeOldBackups(f.config)
+	}
+}
+
+// Close 关闭日志文件，之后写入返回 os.ErrClosed
+func (f *lumberjackFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	if f.mill != nil {
+		close(f.mill)
+	}
+	return f.lj.Close()
+}
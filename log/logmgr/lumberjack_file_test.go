@@ -0,0 +1,187 @@
+package logmgr
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitFor 轮询直到 cond 返回 true，备份在后台压缩和清理
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待超时: %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// writeChecked 写入前重置检查时间，使每次写入都检查文件是否已轮转或被移走
+func writeChecked(t *testing.T, f *lumberjackFile, p []byte) {
+	t.Helper()
+	f.mu.Lock()
+	f.checked = time.Time{}
+	f.mu.Unlock()
+	if _, err := f.Write(p); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLumberjackFileRotate(t *testing.T) {
+	tests := []struct {
+		name     string
+		compress bool
+		codec    string
+		level    int
+		ext      string
+	}{
+		{name: "plain", ext: ""},
+		{name: "gzip", compress: true, ext: ".gz"},
+		{name: "gzip-level", compress: true, codec: CodecGzip, level: 9, ext: ".gz"},
+		{name: "zstd", compress: true, codec: CodecZstd, ext: ".zst"},
+		{name: "zstd-level", compress: true, codec: CodecZstd, level: 19, ext: ".zst"},
+	}
+	record := append(bytes.Repeat([]byte("x"), 600*1024-1), '\n')
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "logs", "app.log")
+			c := LogConfig{
+				FilePath:      path,
+				MaxSize:       1,
+				MaxBackups:    2,
+				Compress:      tt.compress,
+				CompressCodec: tt.codec,
+				CompressLevel: tt.level,
+				DirMode:       "0750",
+				FileMode:      "0640",
+			}
+			f, err := newLumberjackFile(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// 每次写入后超过 1MB，共轮转 5 次
+			for i := 0; i < 6; i++ {
+				writeChecked(t, f, record)
+				time.Sleep(2 * time.Millisecond) // 备份文件名精确到毫秒
+			}
+			writeChecked(t, f, []byte("last\n"))
+			if err := f.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.Write([]byte("closed\n")); err != os.ErrClosed {
+				t.Fatalf("关闭后写入: %v, 期望 os.ErrClosed", err)
+			}
+
+			waitFor(t, "压缩和清理备份", func() bool {
+				files, _ := LogFiles(path)
+				if len(files) != 3 {
+					return false
+				}
+				for _, name := range files[:2] {
+					if !strings.HasSuffix(name, ".log"+tt.ext) {
+						return false
+					}
+				}
+				return true
+			})
+			files, _ := LogFiles(path)
+			for _, name := range files[:2] {
+				r, err := OpenLogFile(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				data, err := io.ReadAll(r)
+				r.Close()
+				if err != nil {
+					t.Fatalf("读取 %s: %v", name, err)
+				}
+				if !bytes.Equal(data, record) {
+					t.Fatalf("%s 内容长度 %d，期望 %d", name, len(data), len(record))
+				}
+			}
+			assertMode(t, path, 0640)
+			assertMode(t, filepath.Dir(path), 0750|os.ModeDir)
+		})
+	}
+}
+
+func TestLumberjackFileMaxAge(t *testing.T) {
+	for _, name := range []string{CodecGzip, CodecZstd} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "app.log")
+			old := backupName(path, time.Now().Add(-48*time.Hour).UTC())
+			recent := backupName(path, time.Now().Add(-time.Hour).UTC())
+			for _, b := range []string{old, recent} {
+				if err := os.WriteFile(b, []byte("backup\n"), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			f, err := newLumberjackFile(LogConfig{FilePath: path, MaxAge: 1, Compress: true, CompressCodec: name})
+			if err != nil {
+				t.Fatal(err)
+			}
+			writeChecked(t, f, []byte("line\n"))
+			f.Close()
+
+			ext := codec{name: name}.ext()
+			waitFor(t, "删除超过 MaxAge 的备份", func() bool {
+				_, errOld := os.Stat(old)
+				_, errOldZ := os.Stat(old + ext)
+				_, errRecent := os.Stat(recent + ext)
+				return os.IsNotExist(errOld) && os.IsNotExist(errOldZ) && errRecent == nil
+			})
+		})
+	}
+}
+
+func TestLumberjackFileReopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	f, err := newLumberjackFile(LogConfig{FilePath: path, FileMode: "0640"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	writeChecked(t, f, []byte("before\n"))
+
+	// 删除和移走(如 logrotate)后都应写入 path 处的新文件
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	writeChecked(t, f, []byte("deleted\n"))
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	writeChecked(t, f, []byte("moved\n"))
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "moved\n" {
+		t.Fatalf("path 内容为 %q", data)
+	}
+	data, _ = os.ReadFile(path + ".1")
+	if string(data) != "deleted\n" {
+		t.Fatalf("移走的文件内容为 %q", data)
+	}
+	assertMode(t, path, 0640)
+}
+
+func assertMode(t *testing.T, path string, want os.FileMode) {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Mode() & (os.ModePerm | os.ModeDir); got != want {
+		t.Fatalf("%s 权限为 %v，期望 %v", path, got, want)
+	}
+}
@@ -0,0 +1,247 @@
+package logmgr
+
+import (
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ModuleKey 统计时用于区分模块的字段名，zap 使用 logger 名称
+const ModuleKey = "module"
+
+// 统计的模块数上限，超过后新出现的模块计入 OtherModule，避免 module 标签的基数随字段取值无限增长
+const maxModules = 100
+
+// OtherModule 超过模块数上限后新模块的统计名称
+const OtherModule = "other"
+
+// 输出名称
+const (
+	SinkConsole = "console"
+	SinkFile    = "file"
+	SinkMemory  = "memory"
+	SinkSocket  = "socket"
+)
+
+// SinkMetrics 单个输出的统计
+type SinkMetrics struct {
+	Writes   uint64 `json:"writes"`   // 写入次数
+	Bytes    uint64 `json:"bytes"`    // 写入字节数
+	Failures uint64 `json:"failures"` // 写入失败次数
+	Fallback uint64 `json:"fallback"` // 写入失败后写入备用输出的记录数
+	Dropped  uint64 `json:"dropped"`  // 丢弃的记录数
+}
+
+// MetricsSnapshot 日志统计快照
+type MetricsSnapshot struct {
+	Records map[string]map[string]uint64 `json:"records"` // 级别 -> 模块 -> 条数
+	Sampled map[string]uint64            `json:"sampled"` // 级别 -> 被采样丢弃的条数
+	Sinks   map[string]SinkMetrics       `json:"sinks"`
+}
+
+type recordKey struct {
+	level  Level
+	module string
+}
+
+type sinkCounters struct {
+	writes, bytes, failures, fallback, dropped atomic.Uint64
+}
+
+var (
+	recordCounters  sync.Map // recordKey -> *atomic.Uint64
+	moduleNames     sync.Map // 已统计的模块名
+	moduleCount     atomic.Int64
+	sampledCounters [FatalLevel - TraceLevel + 1]atomic.Uint64
+	sinkMu          sync.Mutex
+	sinkStats       = map[string]*sinkCounters{}
+	publishOnce     sync.Once
+)
+
+// CountRecord 统计一条输出的日志，模块数超过上限后新的模块计入 OtherModule
+func CountRecord(level Level, module string) {
+	key := recordKey{level: level, module: module}
+	c, ok := recordCounters.Load(key)
+	if !ok {
+		key.module = limitModule(module)
+		c, _ = recordCounters.LoadOrStore(key, new(atomic.Uint64))
+	}
+	c.(*atomic.Uint64).Add(1)
+}
+
+// limitModule 返回统计使用的模块名，模块数达到 maxModules 后新的模块返回 OtherModule
+func limitModule(module string) string {
+	if _, ok := moduleNames.Load(module); ok {
+		return module
+	}
+	if moduleCount.Add(1) > maxModules {
+		moduleCount.Add(-1)
+		return OtherModule
+	}
+	if _, loaded := moduleNames.LoadOrStore(module, struct{}{}); loaded {
+		moduleCount.Add(-1)
+	}
+	return module
+}
+
+// CountSampled 统计被采样丢弃的日志
+func CountSampled(level Level) {
+	if level >= TraceLevel && level <= FatalLevel {
+		sampledCounters[level-TraceLevel].Add(1)
+	}
+}
+
+// CountDropped 统计输出丢弃的记录
+func CountDropped(sink string, n int) {
+	getSinkCounters(sink).dropped.Add(uint64(n))
+}
+
+func getSinkCounters(sink string) *sinkCounters {
+	sinkMu.Lock()
+	defer sinkMu.Unlock()
+	c, ok := sinkStats[sink]
+	if !ok {
+		c = &sinkCounters{}
+		sinkStats[sink] = c
+	}
+	return c
+}
+
+// WrapSink 包装输出: 开启统计时统计写入情况，记录健康状态(见 Health)，写入失败时通知 OnWriteError 并写入 FallbackOutput；
+// 写入备用输出的记录计入 Fallback 而不是 Dropped
+func (c LogConfig) WrapSink(sink string, w io.Writer) io.Writer {
+	var counters *sinkCounters
+	if c.Metrics {
+		PublishMetrics()
+		counters = getSinkCounters(sink)
+	}
+	return &guardedWriter{
+		sink:     sink,
+		out:      w,
+		state:    newSinkState(sink),
+		onError:  c.OnWriteError,
+		fallback: c.newFallbackWriter(),
+		counters: counters,
+	}
+}
+
+// GetMetrics 返回当前的统计快照
+func GetMetrics() MetricsSnapshot {
+	s := MetricsSnapshot{
+		Records: map[string]map[string]uint64{},
+		Sampled: map[string]uint64{},
+		Sinks:   map[string]SinkMetrics{},
+	}
+	recordCounters.Range(func(k, v interface{}) bool {
+		key := k.(recordKey)
+		m, ok := s.Records[key.level.String()]
+		if !ok {
+			m = map[string]uint64{}
+			s.Records[key.level.String()] = m
+		}
+		m[key.module] = v.(*atomic.Uint64).Load()
+		return true
+	})
+	for i := range sampledCounters {
+		if n := sampledCounters[i].Load(); n > 0 {
+			s.Sampled[(TraceLevel + Level(i)).String()] = n
+		}
+	}
+	sinkMu.Lock()
+	for name, c := range sinkStats {
+		s.Sinks[name] = SinkMetrics{
+			Writes:   c.writes.Load(),
+			Bytes:    c.bytes.Load(),
+			Failures: c.failures.Load(),
+			Fallback: c.fallback.Load(),
+			Dropped:  c.dropped.Load(),
+		}
+	}
+	sinkMu.Unlock()
+	return s
+}
+
+// PublishMetrics 将统计以 "logmgr" 为名发布到 expvar，重复调用无影响
+func PublishMetrics() {
+	publishOnce.Do(func() {
+		expvar.Publish("logmgr", expvar.Func(func() interface{} {
+			return GetMetrics()
+		}))
+	})
+}
+
+// MetricsHandler 返回以 Prometheus 文本格式输出统计的 HTTP 处理器
+func MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = io.WriteString(w, PrometheusText(GetMetrics()))
+	})
+}
+
+// PrometheusText 将统计快照编码为 Prometheus 文本格式
+func PrometheusText(s MetricsSnapshot) string {
+	var b strings.Builder
+
+	b.WriteString("# HELP gobox_log_records_total Number of log records emitted by level and module.\n")
+	b.WriteString("# TYPE gobox_log_records_total counter\n")
+	levels := make([]string, 0, len(s.Records))
+	for l := range s.Records {
+		levels = append(levels, l)
+	}
+	sort.Strings(levels)
+	for _, l := range levels {
+		modules := make([]string, 0, len(s.Records[l]))
+		for m := range s.Records[l] {
+			modules = append(modules, m)
+		}
+		sort.Strings(modules)
+		for _, m := range modules {
+			fmt.Fprintf(&b, "gobox_log_records_total{level=\"%s\",module=\"%s\"} %d\n",
+				escapeLabel(l), escapeLabel(m), s.Records[l][m])
+		}
+	}
+
+	b.WriteString("# HELP gobox_log_sampled_total Number of log records dropped by sampling by level.\n")
+	b.WriteString("# TYPE gobox_log_sampled_total counter\n")
+	levels = levels[:0]
+	for l := range s.Sampled {
+		levels = append(levels, l)
+	}
+	sort.Strings(levels)
+	for _, l := range levels {
+		fmt.Fprintf(&b, "gobox_log_sampled_total{level=\"%s\"} %d\n", escapeLabel(l), s.Sampled[l])
+	}
+
+	sinks := make([]string, 0, len(s.Sinks))
+	for name := range s.Sinks {
+		sinks = append(sinks, name)
+	}
+	sort.Strings(sinks)
+	metrics := []struct {
+		name, help string
+		value      func(SinkMetrics) uint64
+	}{
+		{"gobox_log_sink_writes_total", "Number of writes to the sink.", func(m SinkMetrics) uint64 { return m.Writes }},
+		{"gobox_log_sink_bytes_total", "Number of bytes written to the sink.", func(m SinkMetrics) uint64 { return m.Bytes }},
+		{"gobox_log_sink_write_failures_total", "Number of failed writes to the sink.", func(m SinkMetrics) uint64 { return m.Failures }},
+		{"gobox_log_sink_fallback_total", "Number of records written to the fallback output after a failed write.", func(m SinkMetrics) uint64 { return m.Fallback }},
+		{"gobox_log_sink_dropped_total", "Number of records dropped by the sink.", func(m SinkMetrics) uint64 { return m.Dropped }},
+	}
+	for _, metric := range metrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s counter\n", metric.name, metric.help, metric.name)
+		for _, name := range sinks {
+			fmt.Fprintf(&b, "%s{sink=\"%s\"} %d\n", metric.name, escapeLabel(name), metric.value(s.Sinks[name]))
+		}
+	}
+	return b.String()
+}
+
+// escapeLabel 按 Prometheus 文本格式转义标签值
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
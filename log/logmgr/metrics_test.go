@@ -0,0 +1,92 @@
+package logmgr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// failWriter 在 fail 为 true 时写入失败
+type failWriter struct {
+	fail bool
+	buf  bytes.Buffer
+}
+
+func (w *failWriter) Write(p []byte) (int, error) {
+	if w.fail {
+		return 0, errors.New("disk full")
+	}
+	return w.buf.Write(p)
+}
+
+func TestCountSampled(t *testing.T) {
+	before := GetMetrics().Sampled[WarnLevel.String()]
+	s := LogConfig{SamplingInitial: 1, Metrics: true}.NewSampler()
+	s.tick = time.Hour
+	for i := 0; i < 5; i++ {
+		s.Allow(WarnLevel, "sampled-test")
+	}
+	m := GetMetrics()
+	if got := m.Sampled[WarnLevel.String()] - before; got != 4 {
+		t.Fatalf("采样丢弃 %d 条，期望 4", got)
+	}
+	if text := PrometheusText(m); !strings.Contains(text, `gobox_log_sampled_total{level="warn"}`) {
+		t.Fatalf("Prometheus 输出缺少 sampled:\n%s", text)
+	}
+}
+
+func TestSinkMetricsFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		fallback bool
+		want     SinkMetrics
+	}{
+		{name: "dropped", want: SinkMetrics{Writes: 3, Failures: 2, Dropped: 2, Bytes: 2}},
+		{name: "fallback", fallback: true, want: SinkMetrics{Writes: 3, Failures: 2, Fallback: 2, Bytes: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := LogConfig{Metrics: true}
+			if tt.fallback {
+				c.FallbackOutput = t.TempDir() + "/fallback.log"
+			}
+			sink := "metrics-" + tt.name
+			out := &failWriter{fail: true}
+			w := c.WrapSink(sink, out)
+			w.Write([]byte("a"))
+			w.Write([]byte("b"))
+			out.fail = false
+			w.Write([]byte("c\n"))
+
+			if got := GetMetrics().Sinks[sink]; got != tt.want {
+				t.Fatalf("统计 %+v，期望 %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModuleLimit(t *testing.T) {
+	for i := 0; i < maxModules+20; i++ {
+		CountRecord(InfoLevel, fmt.Sprintf("limit-test-%d", i))
+	}
+	m := GetMetrics()
+	modules := 0
+	for _, levels := range m.Records {
+		modules = max(modules, len(levels))
+	}
+	if modules > maxModules+1 {
+		t.Fatalf("统计了 %d 个模块，上限为 %d", modules, maxModules)
+	}
+	if m.Records[InfoLevel.String()][OtherModule] < 20 {
+		t.Fatalf("超过上限的模块未计入 %s: %d", OtherModule, m.Records[InfoLevel.String()][OtherModule])
+	}
+	// 已统计的模块不受影响
+	before := m.Records[InfoLevel.String()]["limit-test-0"]
+	CountRecord(InfoLevel, "limit-test-0")
+	if got := GetMetrics().Records[InfoLevel.String()]["limit-test-0"]; got != before+1 {
+		t.Fatalf("已统计模块的计数为 %d，期望 %d", got, before+1)
+	}
+}
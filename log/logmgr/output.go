@@ -0,0 +1,26 @@
+package logmgr
+
+// OutputConsoleSocket 同时输出到控制台和套接字的 Output 取值
+const OutputConsoleSocket = "console+socket"
+
+// ConsoleEnabled 返回 Output 是否包含控制台: console、both 和 console+socket
+func (c LogConfig) ConsoleEnabled() bool {
+	switch c.Output {
+	case SinkConsole, "both", OutputConsoleSocket:
+		return true
+	}
+	return false
+}
+
+// GetSink 返回 Output 中控制台之外的输出: SinkFile、SinkMemory 或 SinkSocket，没有时返回空
+func (c LogConfig) GetSink() string {
+	switch c.Output {
+	case SinkFile, "both":
+		return SinkFile
+	case SinkMemory:
+		return SinkMemory
+	case SinkSocket, OutputConsoleSocket:
+		return SinkSocket
+	}
+	return ""
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package logmgr
+
+import "os"
+
+// openPipe 打开命名管道的客户端，Windows 上不支持写入超时
+func openPipe(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_WRONLY, 0)
+}
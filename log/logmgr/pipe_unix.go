@@ -0,0 +1,22 @@
+//go:build unix
+
+package logmgr
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// openPipe 以非阻塞方式打开 FIFO 的写端，没有进程打开读端时返回错误，由调用方稍后重试
+func openPipe(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return nil, err
+	}
+	if fi, err := f.Stat(); err != nil || fi.Mode()&os.ModeNamedPipe == 0 {
+		f.Close()
+		return nil, fmt.Errorf("%s 不是命名管道", path)
+	}
+	return f, nil
+}
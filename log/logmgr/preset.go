@@ -0,0 +1,127 @@
+package logmgr
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// EnvVar 选择预设配置的环境变量
+const EnvVar = "APP_ENV"
+
+// 运行环境
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+	EnvTest        = "test"
+)
+
+// Development 开发环境: 带颜色的控制台文本，debug 级别，记录调用位置
+func Development() LogConfig {
+	return LogConfig{
+		Level:           "debug",
+		Output:          "console",
+		ConsoleEncoding: EncodingText,
+	}
+}
+
+// Production 生产环境: JSON 写入 AppLogDir 下的 <appName>.log，info 级别，按大小轮转并压缩，
+// 每秒相同级别和消息的日志超过 100 条后每 100 条记录一条
+func Production(appName string) LogConfig {
+	return LogConfig{
+		Level:              "info",
+		Output:             "file",
+		FilePath:           filepath.Join(AppLogDir(appName), appName+".log"),
+		FileEncoding:       EncodingJSON,
+		MaxSize:            100,
+		MaxBackups:         10,
+		MaxAge:             30,
+		Compress:           true,
+		SamplingInitial:    100,
+		SamplingThereafter: 100,
+	}
+}
+
+// Test 测试环境: JSON 写入内存，debug 级别，每次调用返回单独的捕获
+func Test() (LogConfig, *Capture) {
+	c := testConfig()
+	c.Capture = NewCapture()
+	return c, c.Capture
+}
+
+// testConfig 写入全局捕获的测试环境配置，通过 CapturedLogs 和 CapturedEntries 读取
+func testConfig() LogConfig {
+	return LogConfig{
+		Level:  "debug",
+		Output: "memory",
+	}
+}
+
+// ForEnv 返回运行环境对应的预设配置，支持 dev、prod、testing 等简写。
+// env 为空(未设置环境变量)和未知环境都使用生产环境，避免漏配或拼写错误的生产部署输出 debug 日志，
+// 开发时需要显式设置 dev；测试环境写入全局捕获
+func ForEnv(env, appName string) LogConfig {
+	switch strings.ToLower(strings.TrimSpace(env)) {
+	case "dev", "develop", "local", EnvDevelopment:
+		return Development()
+	case EnvTest, "testing":
+		return testConfig()
+	default:
+		return Production(appName)
+	}
+}
+
+// FromEnv 按环境变量 APP_ENV 选择预设配置，未设置时使用生产环境，本地开发需要设置 APP_ENV=dev
+//
+//	slogmgr.SetupWithColor(logmgr.FromEnv("myapp").Override(logmgr.LogConfig{Level: "warn"}))
+func FromEnv(appName string) LogConfig {
+	return ForEnv(os.Getenv(EnvVar), appName)
+}
+
+// Override 返回用 o 中非零值字段覆盖后的配置，fields 中列出的字段即使为零值也覆盖，
+// 用于将 bool 字段改回 false 或清空字段，字段名不存在时 panic
+//
+//	logmgr.Production("myapp").Override(logmgr.LogConfig{Level: "debug"}, "Compress")
+func (c LogConfig) Override(o LogConfig, fields ...string) LogConfig {
+	dst := reflect.ValueOf(&c).Elem()
+	src := reflect.ValueOf(o)
+	for i := 0; i < src.NumField(); i++ {
+		if f := src.Field(i); !f.IsZero() {
+			dst.Field(i).Set(f)
+		}
+	}
+	for _, name := range fields {
+		f := src.FieldByName(name)
+		if !f.IsValid() {
+			panic("未知的配置字段: " + name)
+		}
+		dst.FieldByName(name).Set(f)
+	}
+	return c
+}
+
+// AppLogDir 返回应用的默认日志目录: Windows 为 %LOCALAPPDATA%\<app>\logs，macOS 为 ~/Library/Logs/<app>，
+// 其他系统为 $XDG_STATE_HOME/<app>/logs(默认 ~/.local/state)，无法确定用户目录时使用当前目录下的 logs
+func AppLogDir(appName string) string {
+	home, _ := os.UserHomeDir()
+	switch runtime.GOOS {
+	case "windows":
+		if dir := os.Getenv("LOCALAPPDATA"); dir != "" {
+			return filepath.Join(dir, appName, "logs")
+		}
+	case "darwin", "ios":
+		if home != "" {
+			return filepath.Join(home, "Library", "Logs", appName)
+		}
+	default:
+		if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+			return filepath.Join(dir, appName, "logs")
+		}
+		if home != "" {
+			return filepath.Join(home, ".local", "state", appName, "logs")
+		}
+	}
+	return "logs"
+}
@@ -0,0 +1,89 @@
+package logmgr
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestForEnv(t *testing.T) {
+	tests := []struct {
+		env    string
+		output string
+	}{
+		{env: "", output: "file"}, // 未设置时按生产环境处理
+		{env: "  ", output: "file"},
+		{env: "dev", output: "console"},
+		{env: " Development ", output: "console"},
+		{env: "local", output: "console"},
+		{env: "prod", output: "file"},
+		{env: "production", output: "file"},
+		{env: "staging", output: "file"},
+		{env: "prd", output: "file"},
+		{env: "live", output: "file"},
+		{env: "testing", output: "memory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			c := ForEnv(tt.env, "app")
+			if c.Output != tt.output {
+				t.Fatalf("ForEnv(%q).Output = %q，期望 %q", tt.env, c.Output, tt.output)
+			}
+			if c.Capture != nil {
+				t.Fatal("ForEnv 应写入全局捕获")
+			}
+		})
+	}
+}
+
+func TestOverride(t *testing.T) {
+	base := LogConfig{Level: "info", FilePath: "app.log", Compress: true, Metrics: true}
+	tests := []struct {
+		name   string
+		o      LogConfig
+		fields []string
+		want   LogConfig
+	}{
+		{name: "non zero", o: LogConfig{Level: "debug"}, want: LogConfig{Level: "debug", FilePath: "app.log", Compress: true, Metrics: true}},
+		{name: "zero ignored", o: LogConfig{Compress: false}, want: base},
+		{name: "bool to false", o: LogConfig{Level: "warn"}, fields: []string{"Compress"}, want: LogConfig{Level: "warn", FilePath: "app.log", Metrics: true}},
+		{name: "clear string", fields: []string{"FilePath", "Metrics"}, want: LogConfig{Level: "info", Compress: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := base.Override(tt.o, tt.fields...)
+			if fmt.Sprintf("%+v", got) != fmt.Sprintf("%+v", tt.want) {
+				t.Fatalf("得到 %+v\n期望 %+v", got, tt.want)
+			}
+		})
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("未知字段应 panic")
+		}
+	}()
+	base.Override(LogConfig{}, "Compres")
+}
+
+func TestTestCapture(t *testing.T) {
+	for i := 0; i < 4; i++ {
+		i := i
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			t.Parallel()
+			c, capture := Test()
+			w := c.CaptureWriter()
+			for j := 0; j <= i; j++ {
+				fmt.Fprintf(w, `{"level":"info","message":"m%d"}`+"\n", i)
+			}
+			entries := capture.Entries()
+			if len(entries) != i+1 {
+				t.Fatalf("捕获 %d 条，期望 %d 条", len(entries), i+1)
+			}
+			for _, e := range entries {
+				if e.Message != fmt.Sprintf("m%d", i) {
+					t.Fatalf("捕获到其他测试的日志 %q", e.Message)
+				}
+			}
+		})
+	}
+}
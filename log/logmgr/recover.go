@@ -0,0 +1,103 @@
+package logmgr
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 记录 panic 时跳过的栈帧: Recover 本身以及 runtime 中的 panic 处理
+var recoverSkipPrefixes = []string{
+	"github.com/52debug/go-box/log/logmgr.",
+	"runtime.",
+}
+
+var (
+	syncMu   sync.Mutex
+	syncFunc func() error
+)
+
+// SetSync 设置刷新当前日志后端的函数，由各日志管理器在 Setup 时调用，nil 表示无需刷新
+func SetSync(fn func() error) {
+	syncMu.Lock()
+	syncFunc = fn
+	syncMu.Unlock()
+}
+
+// Sync 刷新当前日志后端缓冲的日志，并等待套接字输出缓冲的记录发送完
+func Sync() error {
+	syncMu.Lock()
+	fn := syncFunc
+	syncMu.Unlock()
+	var err error
+	if fn != nil {
+		err = fn()
+	}
+	if serr := flushSocket(); err == nil {
+		err = serr
+	}
+	return err
+}
+
+// Recover 捕获 panic，并通过当前日志后端以 panic 级别记录完整堆栈和 goroutine ID，之后刷新日志
+//
+// 必须直接通过 defer 调用: defer logmgr.Recover()。没有 panic 时什么也不做，捕获后不会再次 panic
+func Recover() {
+	if r := recover(); r != nil {
+		logPanic(r)
+	}
+}
+
+// Go 在新的 goroutine 中运行 fn，fn 发生 panic 时记录日志而不会导致进程退出
+func Go(fn func()) {
+	go func() {
+		defer Recover()
+		fn()
+	}()
+}
+
+// logPanic 记录 panic 的值和堆栈
+func logPanic(r interface{}) {
+	ctx := ContextWithStacktrace(context.Background(), Stacktrace(0, recoverSkipPrefixes...))
+	level := PanicLevel.SlogLevel()
+	h := slog.Default().Handler()
+	if h.Enabled(ctx, level) {
+		rec := slog.NewRecord(time.Now(), level, "捕获到 panic", panicPC())
+		rec.AddAttrs(
+			slog.String("panic", fmt.Sprint(r)),
+			slog.Int64("goroutine", goroutineID()),
+		)
+		_ = h.Handle(ctx, rec)
+	}
+	_ = Sync()
+}
+
+// panicPC 返回引发 panic 的调用位置
+func panicPC() uintptr {
+	var pcs [64]uintptr
+	n := runtime.Callers(3, pcs[:])
+	for _, pc := range pcs[:n] {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		if !hasAnyPrefix(frame.Function, recoverSkipPrefixes) {
+			return pc
+		}
+	}
+	return 0
+}
+
+// goroutineID 从 runtime.Stack 的第一行 "goroutine N [...]" 中解析当前 goroutine ID
+func goroutineID() int64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	s := strings.TrimPrefix(string(buf[:n]), "goroutine ")
+	if idx := strings.IndexByte(s, ' '); idx > 0 {
+		id, _ := strconv.ParseInt(s[:idx], 10, 64)
+		return id
+	}
+	return 0
+}
@@ -0,0 +1,73 @@
+// 使用外部测试包，包内函数的栈帧会被当作日志库内部栈帧跳过
+package logmgr_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/52debug/go-box/log/logmgr"
+	"github.com/52debug/go-box/log/slogmgr"
+)
+
+// panicking 在 Go 启动的 goroutine 中 panic
+func panicking() {
+	panic("boom")
+}
+
+func TestGo(t *testing.T) {
+	c, capture := logmgr.Test()
+	slogmgr.Setup(c)
+
+	logmgr.Go(panicking)
+	deadline := time.Now().Add(5 * time.Second)
+	for len(capture.Entries()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("没有记录 panic")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	entries := capture.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("记录 %d 条日志", len(entries))
+	}
+	e := entries[0]
+	if e.Level != logmgr.PanicLevel || e.Level < logmgr.ErrorLevel {
+		t.Fatalf("级别 %v，期望 %v", e.Level, logmgr.PanicLevel)
+	}
+	if e.Fields["panic"] != "boom" || e.Fields["goroutine"] == nil {
+		t.Fatalf("字段 %v", e.Fields)
+	}
+	// 堆栈和调用位置指向引发 panic 的函数
+	if !strings.HasPrefix(e.Stack, "github.com/52debug/go-box/log/logmgr_test.panicking\n") {
+		t.Fatalf("堆栈 %q", e.Stack)
+	}
+	if !strings.HasPrefix(e.Caller, "logmgr/recover_test.go:") {
+		t.Fatalf("调用位置 %q", e.Caller)
+	}
+}
+
+func TestRecoverWithoutPanic(t *testing.T) {
+	c, capture := logmgr.Test()
+	slogmgr.Setup(c)
+
+	// 没有 panic 时什么也不做
+	func() {
+		defer logmgr.Recover()
+	}()
+	logmgr.Recover()
+	if logs := capture.Logs(); len(logs) != 0 {
+		t.Fatalf("记录了日志 %q", logs)
+	}
+
+	// Recover 之后函数正常返回
+	returned := func() (ok bool) {
+		defer logmgr.Recover()
+		defer func() { ok = true }()
+		panic("boom")
+	}()
+	if !returned || len(capture.Entries()) != 1 {
+		t.Fatalf("返回 %v，记录 %d 条日志", returned, len(capture.Entries()))
+	}
+}
@@ -0,0 +1,67 @@
+package logmgr
+
+import (
+	"hash/fnv"
+	"sync/atomic"
+	"time"
+)
+
+// 每个级别的计数桶数，不同消息可能共用一个桶
+const samplerBuckets = 4096
+
+// Sampler 按级别和消息采样: 每秒内相同级别和消息的日志先全部记录 SamplingInitial 条，
+// 之后每 SamplingThereafter 条记录一条，与 zap 的采样规则相同
+type Sampler struct {
+	first, thereafter uint64
+	tick              time.Duration
+	metrics           bool // 开启统计时计入 CountSampled
+	counts            [FatalLevel - TraceLevel + 1][samplerBuckets]samplerCounter
+}
+
+type samplerCounter struct {
+	resetAt atomic.Int64
+	count   atomic.Uint64
+}
+
+// NewSampler 按配置创建采样器，未开启采样(SamplingInitial <= 0)时返回 nil
+func (c LogConfig) NewSampler() *Sampler {
+	if c.SamplingInitial <= 0 {
+		return nil
+	}
+	thereafter := c.SamplingThereafter
+	if thereafter < 0 {
+		thereafter = 0
+	}
+	return &Sampler{first: uint64(c.SamplingInitial), thereafter: uint64(thereafter), tick: time.Second, metrics: c.Metrics}
+}
+
+// Allow 返回是否记录该日志
+func (s *Sampler) Allow(level Level, msg string) bool {
+	if level < TraceLevel || level > FatalLevel {
+		return true
+	}
+	h := fnv.New32a()
+	h.Write([]byte(msg))
+	c := &s.counts[level-TraceLevel][h.Sum32()%samplerBuckets]
+	n := c.inc(time.Now().UnixNano(), s.tick.Nanoseconds())
+	if n <= s.first || s.thereafter > 0 && (n-s.first)%s.thereafter == 0 {
+		return true
+	}
+	if s.metrics {
+		CountSampled(level)
+	}
+	return false
+}
+
+// inc 增加计数并返回当前周期内的条数，周期结束时重新计数
+func (c *samplerCounter) inc(now, tick int64) uint64 {
+	resetAt := c.resetAt.Load()
+	if resetAt > now {
+		return c.count.Add(1)
+	}
+	c.count.Store(1)
+	if !c.resetAt.CompareAndSwap(resetAt, now+tick) {
+		return c.count.Add(1)
+	}
+	return 1
+}
@@ -0,0 +1,56 @@
+package logmgr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSampler(t *testing.T) {
+	tests := []struct {
+		name       string
+		initial    int
+		thereafter int
+		n          int
+		want       int // 记录的条数
+	}{
+		{name: "initial only", initial: 3, n: 10, want: 3},
+		{name: "thereafter", initial: 2, thereafter: 3, n: 11, want: 2 + 3},
+		{name: "every", initial: 1, thereafter: 1, n: 5, want: 5},
+		{name: "below initial", initial: 10, n: 5, want: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := LogConfig{SamplingInitial: tt.initial, SamplingThereafter: tt.thereafter}.NewSampler()
+			s.tick = time.Hour // 测试期间不重新计数
+			got := 0
+			for i := 0; i < tt.n; i++ {
+				if s.Allow(InfoLevel, "msg") {
+					got++
+				}
+			}
+			if got != tt.want {
+				t.Fatalf("记录 %d 条，期望 %d", got, tt.want)
+			}
+			// 不同级别和消息分别计数
+			if !s.Allow(WarnLevel, "msg") || !s.Allow(InfoLevel, "other") {
+				t.Fatal("不同级别或消息的首条记录被丢弃")
+			}
+		})
+	}
+
+	if (LogConfig{}).NewSampler() != nil {
+		t.Fatal("未开启采样时 NewSampler 应返回 nil")
+	}
+}
+
+func TestSamplerReset(t *testing.T) {
+	s := LogConfig{SamplingInitial: 1}.NewSampler()
+	s.tick = 20 * time.Millisecond
+	if !s.Allow(InfoLevel, "m") || s.Allow(InfoLevel, "m") {
+		t.Fatal("同一周期内超过 SamplingInitial 的记录应被丢弃")
+	}
+	time.Sleep(2 * s.tick)
+	if !s.Allow(InfoLevel, "m") {
+		t.Fatal("新周期的首条记录被丢弃")
+	}
+}
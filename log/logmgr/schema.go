@@ -0,0 +1,123 @@
+package logmgr
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// 内置字段映射
+const (
+	SchemaNative = "native" // 各后端原生字段名
+	SchemaECS    = "ecs"    // Elastic Common Schema
+	SchemaGCP    = "gcp"    // Google Cloud Logging 结构化日志
+)
+
+// Schema JSON 输出中内置字段的名称、级别取值和默认时间格式
+type Schema struct {
+	Keys       FieldKeys        // 内置字段名，为空的字段使用后端原生名称
+	Levels     map[Level]string // 级别的输出值，未包含的级别使用小写级别名
+	TimeFormat string           // 未配置 LogConfig.TimeFormat 时使用的时间格式，为空时使用默认格式
+	TimeZone   string           // 未配置 LogConfig.TimeFormat 和 TimeZone 时使用的时区，为空时使用本地时区
+}
+
+// 内置的字段映射
+var (
+	// ECSSchema Elastic Common Schema，字段名中的 . 由 Elasticsearch 展开为对象
+	ECSSchema = Schema{
+		Keys: FieldKeys{
+			Time:       "@timestamp",
+			Level:      "log.level",
+			Message:    "message",
+			Caller:     "log.origin.file.name",
+			Stacktrace: "error.stack_trace",
+		},
+		TimeFormat: TimeFormatRFC3339Nano,
+		TimeZone:   "utc",
+	}
+
+	// GCPSchema Google Cloud Logging，级别使用 severity 的取值
+	//
+	// sourceLocation 需要对象格式，调用位置仍保留在 caller 字段中。
+	GCPSchema = Schema{
+		Keys: FieldKeys{
+			Time:       "time",
+			Level:      "severity",
+			Message:    "message",
+			Caller:     "caller",
+			Stacktrace: "stack_trace",
+		},
+		Levels: map[Level]string{
+			TraceLevel: "DEBUG",
+			DebugLevel: "DEBUG",
+			InfoLevel:  "INFO",
+			WarnLevel:  "WARNING",
+			ErrorLevel: "ERROR",
+			PanicLevel: "CRITICAL",
+			FatalLevel: "ALERT",
+		},
+		TimeFormat: TimeFormatRFC3339Nano,
+		TimeZone:   "utc",
+	}
+)
+
+var (
+	schemaMu sync.RWMutex
+	schemas  = map[string]Schema{
+		SchemaNative: {},
+		SchemaECS:    ECSSchema,
+		SchemaGCP:    GCPSchema,
+	}
+)
+
+// RegisterSchema 注册自定义字段映射，之后可在 LogConfig.Schema 中按名称引用
+func RegisterSchema(name string, schema Schema) {
+	schemaMu.Lock()
+	defer schemaMu.Unlock()
+	schemas[strings.ToLower(name)] = schema
+}
+
+// LookupSchema 按名称查找字段映射
+func LookupSchema(name string) (Schema, bool) {
+	schemaMu.RLock()
+	defer schemaMu.RUnlock()
+	s, ok := schemas[strings.ToLower(strings.TrimSpace(name))]
+	return s, ok
+}
+
+// GetSchema 返回配置的字段映射，未配置或未知名称时使用原生字段名(未知名称由 Validate 报告)
+func (c LogConfig) GetSchema() Schema {
+	s, _ := LookupSchema(c.Schema)
+	return s
+}
+
+// LevelName 返回级别的输出值
+func (s Schema) LevelName(l Level) string {
+	if name, ok := s.Levels[l]; ok {
+		return name
+	}
+	return l.String()
+}
+
+// SlogLevelName 返回 slog 级别的输出值，不对应 Level 的级别保持 slog 的表示(如 info+2)
+func (s Schema) SlogLevelName(l slog.Level) string {
+	if lv := LevelFromSlog(l); lv.SlogLevel() == l {
+		return s.LevelName(lv)
+	}
+	return SlogLevelName(l)
+}
+
+// ParseLevel 解析 LevelName 输出的级别，多个级别使用相同取值时返回最高的级别
+func (s Schema) ParseLevel(name string) (Level, error) {
+	found := false
+	var level Level
+	for l, n := range s.Levels {
+		if strings.EqualFold(n, name) && (!found || l > level) {
+			level, found = l, true
+		}
+	}
+	if found {
+		return level, nil
+	}
+	return ParseLevel(name)
+}
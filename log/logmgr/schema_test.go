@@ -0,0 +1,94 @@
+package logmgr
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSchemaTimeEncoder(t *testing.T) {
+	RegisterSchema("schema-test", Schema{Keys: FieldKeys{Time: "ts"}})
+	tests := []struct {
+		name    string
+		config  LogConfig
+		layout  string
+		numeric bool
+		loc     *time.Location
+	}{
+		{name: "native", config: LogConfig{}, layout: DefaultTimeLayout, loc: time.Local},
+		{name: "ecs", config: LogConfig{Schema: SchemaECS}, layout: time.RFC3339Nano, loc: time.UTC},
+		{name: "gcp", config: LogConfig{Schema: "GCP"}, layout: time.RFC3339Nano, loc: time.UTC},
+		// 单独配置的时区和时间格式优先
+		{name: "ecs local", config: LogConfig{Schema: SchemaECS, TimeZone: "local"}, layout: time.RFC3339Nano, loc: time.Local},
+		{name: "ecs format", config: LogConfig{Schema: SchemaECS, TimeFormat: "unixms"}, numeric: true, loc: time.Local},
+		{name: "registered", config: LogConfig{Schema: "schema-test"}, layout: DefaultTimeLayout, loc: time.Local},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc, err := tt.config.NewTimeEncoder()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if enc.Layout() != tt.layout || enc.Numeric() != tt.numeric || enc.Location() != tt.loc {
+				t.Fatalf("格式 %q 数值 %v 时区 %v，期望 %q %v %v", enc.Layout(), enc.Numeric(), enc.Location(), tt.layout, tt.numeric, tt.loc)
+			}
+		})
+	}
+}
+
+func TestSchemaLevels(t *testing.T) {
+	tests := []struct {
+		schema Schema
+		level  Level
+		name   string
+		parsed Level // 解析 name 得到的级别
+	}{
+		{ECSSchema, WarnLevel, "warn", WarnLevel},
+		{ECSSchema, ErrorLevel, "error", ErrorLevel},
+		{GCPSchema, TraceLevel, "DEBUG", DebugLevel}, // 多个级别取值相同时解析为最高的级别
+		{GCPSchema, DebugLevel, "DEBUG", DebugLevel},
+		{GCPSchema, InfoLevel, "INFO", InfoLevel},
+		{GCPSchema, WarnLevel, "WARNING", WarnLevel},
+		{GCPSchema, ErrorLevel, "ERROR", ErrorLevel},
+		{GCPSchema, PanicLevel, "CRITICAL", PanicLevel},
+		{GCPSchema, FatalLevel, "ALERT", FatalLevel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schema.LevelName(tt.level); got != tt.name {
+				t.Fatalf("LevelName(%v) = %q，期望 %q", tt.level, got, tt.name)
+			}
+			if got, err := tt.schema.ParseLevel(strings.ToLower(tt.name)); err != nil || got != tt.parsed {
+				t.Fatalf("ParseLevel(%q) = %v, %v，期望 %v", tt.name, got, err, tt.parsed)
+			}
+		})
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	tests := []struct {
+		schema  string
+		wantErr bool
+	}{
+		{schema: ""},
+		{schema: "native"},
+		{schema: " ECS "},
+		{schema: "gcp"},
+		{schema: "elastic", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.schema, func(t *testing.T) {
+			c := LogConfig{Schema: tt.schema}
+			err := c.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v，期望错误: %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "字段映射") {
+				t.Fatalf("错误信息: %v", err)
+			}
+			if got := c.Checked().Schema; tt.wantErr && got != "" {
+				t.Fatalf("Checked().Schema = %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package logmgr
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	megabyte       = 1024 * 1024
+	defaultMaxSize = 100 // 与 lumberjack 默认值相同(MB)
+)
+
+// 锁文件头: 轮转次数(8) | 当前文件大小(8)，大端，只在持有文件锁时读写
+const lockHeaderSize = 16
+
+// sharedFile 可由多个进程同时写入和轮转的日志文件
+//
+// 每次写入前对 path+".lock" 加排他锁，从锁文件头读取轮转次数和文件大小，已被其他进程轮转时重新打开，
+// 超过大小时由持锁的进程轮转，备份文件名与 lumberjack 相同。每隔 reopenCheckInterval 检查文件是否被删除或移走，
+// 并按实际大小校正锁文件头。备份的删除和压缩完成后的重命名也在持锁时进行。
+type sharedFile struct {
+	mu      sync.Mutex
+	config  LogConfig
+	perm    filePerm
+	codec   codec
+	lock    *os.File
+	file    *os.File
+	gen     uint64    // 打开 file 时的轮转次数
+	checked time.Time // 最近一次检查 path 的时间
+	closed  bool
+
+	cleanups sync.WaitGroup // 进行中的压缩和清理，Close 时等待
+}
+
+// newSharedFile 按配置打开多进程共享的日志文件
+func newSharedFile(c LogConfig) (*sharedFile, error) {
+	perm, err := c.filePerm()
+	if err != nil {
+		return nil, err
+	}
+	cd, err := c.codec()
+	if err != nil {
+		return nil, err
+	}
+	if err := perm.mkdir(filepath.Dir(c.FilePath)); err != nil {
+		return nil, err
+	}
+	lock, err := perm.openFile(c.FilePath+".lock", os.O_RDWR|os.O_CREATE)
+	if err != nil {
+		return nil, err
+	}
+	// 提前检查平台是否支持
+	if err := lockFile(lock); err != nil {
+		lock.Close()
+		return nil, err
+	}
+	unlockFile(lock)
+	f := &sharedFile{config: c, perm: perm, codec: cd, lock: lock}
+	// 立即创建文件，使权限和所有者在 RedirectStderr 等打开文件之前生效
+	if err := f.locked(func() error {
+		gen, _, _ := f.readHeader()
+		_, err := f.sync(gen)
+		return err
+	}); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *sharedFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+
+	if err := lockFile(f.lock); err != nil {
+		return 0, err
+	}
+	backup, n, err := f.writeLocked(p)
+	unlockFile(f.lock)
+	if backup != "" {
+		f.cleanups.Add(1)
+		go f.cleanup(backup)
+	}
+	return n, err
+}
+
+// locked 在持有进程内的互斥锁和文件锁时调用 fn，同一进程的 goroutine 共用锁文件描述符，仅靠 flock 无法互斥
+func (f *sharedFile) locked(fn func() error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := lockFile(f.lock); err != nil {
+		return err
+	}
+	defer unlockFile(f.lock)
+	return fn()
+}
+
+// writeLocked 在持有文件锁时写入，发生轮转时返回备份文件名
+func (f *sharedFile) writeLocked(p []byte) (backup string, n int, err error) {
+	gen, size, ok := f.readHeader()
+	if !ok || f.file == nil || gen != f.gen || time.Since(f.checked) >= reopenCheckInterval {
+		if size, err = f.sync(gen); err != nil {
+			return "", 0, err
+		}
+	}
+	if size > 0 && size+int64(len(p)) > f.maxSize() {
+		if backup, err = f.rotate(); err != nil {
+			return "", 0, err
+		}
+		size = 0
+	}
+	n, err = f.file.Write(p)
+	f.writeHeader(f.gen, size+int64(n))
+	if err != nil {
+		// 下次写入时检查文件是否仍然有效
+		f.checked = time.Time{}
+	}
+	return backup, n, err
+}
+
+// sync 确保打开的是 path 指向的文件，返回文件的实际大小
+//
+// 文件在本进程打开期间被删除或移走(而不是由其他进程轮转)时增加轮转次数，使其他进程也重新打开。
+func (f *sharedFile) sync(gen uint64) (int64, error) {
+	f.checked = time.Now()
+	if !f.current() {
+		if f.file != nil && gen == f.gen {
+			gen++
+		}
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	f.gen = gen
+	info, err := f.file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	f.writeHeader(f.gen, info.Size())
+	return info.Size(), nil
+}
+
+// readHeader 读取锁文件头，锁文件为新建时 ok 为 false
+func (f *sharedFile) readHeader() (gen uint64, size int64, ok bool) {
+	var hdr [lockHeaderSize]byte
+	if n, _ := f.lock.ReadAt(hdr[:], 0); n < lockHeaderSize {
+		return 0, 0, false
+	}
+	return binary.BigEndian.Uint64(hdr[:8]), int64(binary.BigEndian.Uint64(hdr[8:])), true
+}
+
+func (f *sharedFile) writeHeader(gen uint64, size int64) {
+	var hdr [lockHeaderSize]byte
+	binary.BigEndian.PutUint64(hdr[:8], gen)
+	binary.BigEndian.PutUint64(hdr[8:], uint64(size))
+	f.lock.WriteAt(hdr[:], 0)
+}
+
+// current 已打开的文件是否仍是 path 指向的文件
+func (f *sharedFile) current() bool {
+	if f.file == nil {
+		return false
+	}
+	opened, err := f.file.Stat()
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(f.config.FilePath)
+	return err == nil && os.SameFile(opened, info)
+}
+
+func (f *sharedFile) open() error {
+	file, err := f.perm.openFile(f.config.FilePath, os.O_WRONLY|os.O_APPEND|os.O_CREATE)
+	if err != nil {
+		return err
+	}
+	if f.file != nil {
+		f.file.Close()
+	}
+	f.file = file
+	return nil
+}
+
+// rotate 将当前文件重命名为备份文件并打开新文件，增加轮转次数
+func (f *sharedFile) rotate() (string, error) {
+	backup := freeBackupName(f.config.FilePath, time.Now().UTC())
+	if err := os.Rename(f.config.FilePath, backup); err != nil {
+		return "", err
+	}
+	if err := f.open(); err != nil {
+		return "", err
+	}
+	f.gen++
+	return backup, nil
+}
+
+func (f *sharedFile) maxSize() int64 {
+	if f.config.MaxSize <= 0 {
+		return defaultMaxSize * megabyte
+	}
+	return int64(f.config.MaxSize) * megabyte
+}
+
+// cleanup 压缩本进程轮转出的备份文件，并按 MaxBackups 和 MaxAge 删除旧备份
+//
+// 每个备份只由轮转它的进程压缩，压缩期间不持有锁，备份在此期间被其他进程删除时放弃压缩结果。
+func (f *sharedFile) cleanup(backup string) {
+	defer f.cleanups.Done()
+	if f.config.Compress {
+		if err := compressFile(backup, f.codec, f.perm, f.locked); err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "压缩日志文件失败: %v\n", err)
+		}
+	}
+	f.locked(func() error {
+		removeOldBackups(f.config)
+		return nil
+	})
+}
+
+// Close 等待本进程轮转出的备份压缩和清理完成后关闭日志文件和锁文件，之后写入返回 os.ErrClosed
+func (f *sharedFile) Close() error {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return nil
+	}
+	f.closed = true
+	f.mu.Unlock()
+	f.cleanups.Wait()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+	return f.lock.Close()
+}
+
+// backupName 返回与 lumberjack 相同格式的备份文件名
+func backupName(path string, t time.Time) string {
+	dir := filepath.Dir(path)
+	name := filepath.Base(path)
+	ext := filepath.Ext(name)
+	return filepath.Join(dir, name[:len(name)-len(ext)]+"-"+t.Format(backupTimeFormat)+ext)
+}
+
+// freeBackupName 返回未被使用的备份文件名，同一毫秒内多次轮转时依次后延一毫秒，避免覆盖已有备份
+func freeBackupName(path string, t time.Time) string {
+	for {
+		name := backupName(path, t)
+		if !backupExists(name) {
+			return name
+		}
+		t = t.Add(time.Millisecond)
+	}
+}
+
+// backupExists 备份文件或其压缩文件(包括正在压缩的临时文件)是否存在
+func backupExists(name string) bool {
+	for _, n := range []string{name, name + ".gz", name + ".gz.tmp", name + ".zst", name + ".zst.tmp"} {
+		if _, err := os.Lstat(n); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// compressFile 按 cd 压缩文件并删除原文件，压缩完成前使用临时文件名，避免被当作备份读取
+//
+// locked 非 nil 时在其中将临时文件重命名并删除原文件，原文件此时已被删除则放弃压缩结果。
+func compressFile(path string, cd codec, perm filePerm, locked func(func() error) error) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dstPath := path + cd.ext()
+	tmp := dstPath + ".tmp"
+	dst, err := perm.openFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return err
+	}
+	zw, err := cd.newWriter(dst)
+	if err == nil {
+		_, err = io.Copy(zw, src)
+		if cerr := zw.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	finish := func() error {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			os.Remove(tmp)
+			return nil
+		}
+		if err := os.Rename(tmp, dstPath); err != nil {
+			os.Remove(tmp)
+			return err
+		}
+		return os.Remove(path)
+	}
+	if locked == nil {
+		return finish()
+	}
+	if err := locked(finish); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+//go:build linux
+
+package logmgr
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile 对文件加排他锁(flock)，阻塞直到获得锁
+func lockFile(f *os.File) error {
+	for {
+		err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
+
+func unlockFile(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
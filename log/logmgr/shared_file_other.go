@@ -0,0 +1,17 @@
+//go:build !linux
+
+package logmgr
+
+import (
+	"errors"
+	"os"
+)
+
+// lockFile 非 Linux 平台不支持多进程模式
+func lockFile(*os.File) error {
+	return errors.New("多进程模式仅支持 Linux")
+}
+
+func unlockFile(*os.File) error {
+	return nil
+}
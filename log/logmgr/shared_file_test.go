@@ -0,0 +1,186 @@
+//go:build linux
+
+package logmgr
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 每个 sharedFile 单独打开锁文件，文件锁在它们之间的效果与多个进程相同
+func TestSharedFileConcurrentRotate(t *testing.T) {
+	tests := []struct {
+		name     string
+		compress bool
+		codec    string
+	}{
+		{name: "plain"},
+		{name: "gzip", compress: true},
+		{name: "zstd", compress: true, codec: CodecZstd},
+	}
+	const writers, records = 4, 400
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			c := LogConfig{FilePath: path, MaxSize: 1, MultiProcess: true, Compress: tt.compress, CompressCodec: tt.codec}
+			files := make([]*sharedFile, writers)
+			for i := range files {
+				f, err := newSharedFile(c)
+				if err != nil {
+					t.Fatal(err)
+				}
+				files[i] = f
+			}
+			pad := strings.Repeat("x", 2048)
+			var wg sync.WaitGroup
+			for i, f := range files {
+				wg.Add(1)
+				go func(i int, f *sharedFile) {
+					defer wg.Done()
+					for j := 0; j < records; j++ {
+						if _, err := fmt.Fprintf(f, "%d-%d %s\n", i, j, pad); err != nil {
+							t.Error(err)
+							return
+						}
+					}
+				}(i, f)
+			}
+			wg.Wait()
+			for _, f := range files {
+				f.Close()
+			}
+
+			// 所有记录恰好出现一次，且每个文件不超过 MaxSize
+			waitFor(t, "压缩备份", func() bool {
+				names, _ := LogFiles(path)
+				for _, name := range names[:len(names)-1] {
+					if tt.compress && trimCompressExt(name) == name {
+						return false
+					}
+				}
+				return true
+			})
+			names, err := LogFiles(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(names) < 3 {
+				t.Fatalf("只有 %d 个文件，期望多次轮转", len(names))
+			}
+			seen := map[string]bool{}
+			for _, name := range names {
+				r, err := OpenLogFile(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				size := 0
+				sc := bufio.NewScanner(r)
+				sc.Buffer(nil, 1<<20)
+				for sc.Scan() {
+					line := sc.Text()
+					size += len(line) + 1
+					id, rest, ok := strings.Cut(line, " ")
+					if !ok || rest != pad {
+						t.Fatalf("%s 中的记录不完整: %.40q", name, line)
+					}
+					if seen[id] {
+						t.Fatalf("记录 %s 重复", id)
+					}
+					seen[id] = true
+				}
+				r.Close()
+				if size > megabyte {
+					t.Fatalf("%s 大小 %d 超过 MaxSize", name, size)
+				}
+			}
+			if len(seen) != writers*records {
+				t.Fatalf("共 %d 条记录，期望 %d", len(seen), writers*records)
+			}
+		})
+	}
+}
+
+func TestSharedFileReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	c := LogConfig{FilePath: path, MultiProcess: true}
+	a, err := newSharedFile(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	b, err := newSharedFile(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	// a 发现文件被删除后重新创建，b 通过锁文件头得知并在下次写入时重新打开
+	a.mu.Lock()
+	a.checked = time.Time{}
+	a.mu.Unlock()
+	fmt.Fprintln(a, "a")
+	fmt.Fprintln(b, "b")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "a\nb\n" {
+		t.Fatalf("文件内容为 %q", data)
+	}
+
+	a.Close()
+	if _, err := a.Write([]byte("closed\n")); err != os.ErrClosed {
+		t.Fatalf("关闭后写入: %v, 期望 os.ErrClosed", err)
+	}
+}
+
+func TestFreeBackupName(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	now := time.Date(2024, 1, 2, 3, 4, 5, 6e6, time.UTC)
+	tests := []struct {
+		name     string
+		existing []string // 已存在的备份，相对 now 的毫秒偏移
+		want     time.Duration
+	}{
+		{name: "free", want: 0},
+		{name: "plain", existing: []string{"0"}, want: time.Millisecond},
+		{name: "compressed", existing: []string{"0.gz", "1.zst"}, want: 2 * time.Millisecond},
+		{name: "compressing", existing: []string{"0.gz.tmp"}, want: time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created []string
+			for _, e := range tt.existing {
+				var ms int
+				var ext string
+				if i := strings.IndexByte(e, '.'); i >= 0 {
+					ext = e[i:]
+					e = e[:i]
+				}
+				fmt.Sscan(e, &ms)
+				name := backupName(path, now.Add(time.Duration(ms)*time.Millisecond)) + ext
+				if err := os.WriteFile(name, nil, 0600); err != nil {
+					t.Fatal(err)
+				}
+				created = append(created, name)
+			}
+			defer func() {
+				for _, name := range created {
+					os.Remove(name)
+				}
+			}()
+			if got, want := freeBackupName(path, now), backupName(path, now.Add(tt.want)); got != want {
+				t.Fatalf("得到 %s，期望 %s", filepath.Base(got), filepath.Base(want))
+			}
+		})
+	}
+}
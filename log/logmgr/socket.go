@@ -0,0 +1,219 @@
+package logmgr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 套接字类型
+const (
+	SocketStream   = "stream"   // SOCK_STREAM，记录以换行分隔
+	SocketDatagram = "datagram" // SOCK_DGRAM，每条记录一个数据报
+	SocketPipe     = "pipe"     // 命名管道: Unix 为 mkfifo 创建的 FIFO，Windows 为 \\.\pipe\<name>，记录以换行分隔
+)
+
+const (
+	defaultSocketBuffer = 1024
+	socketDialTimeout   = time.Second
+	socketWriteTimeout  = 5 * time.Second
+	socketMinBackoff    = 100 * time.Millisecond
+	socketMaxBackoff    = 5 * time.Second
+	socketMaxAttempts   = 3               // 单条记录的最大发送次数，超过后丢弃(如数据报过大)
+	socketFlushTimeout  = 2 * time.Second // Sync 等待缓冲区发送完的最长时间
+)
+
+var (
+	errSocketBufferFull = errors.New("日志套接字缓冲区已满")
+	errSocketClosed     = errors.New("日志套接字已关闭")
+)
+
+// socketConn 套接字连接或打开的命名管道
+type socketConn interface {
+	io.WriteCloser
+	SetWriteDeadline(t time.Time) error
+}
+
+// socketWriter 将记录写入 Unix 套接字或命名管道，连接断开时自动重连
+//
+// Write 将记录放入有界缓冲区后立即返回，由后台 goroutine 发送，缓冲区满时返回错误。
+type socketWriter struct {
+	network string
+	path    string
+	max     int
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queue  [][]byte
+	closed bool
+	done   chan struct{}
+	conn   socketConn // 仅由发送 goroutine 使用
+}
+
+// 当前配置的套接字输出，由 Sync 刷新，重新配置时关闭之前的输出
+var activeSocket atomic.Pointer[socketWriter]
+
+// NewSocketWriter 按 SocketPath、SocketType 和 SocketBuffer 创建写入 Unix 套接字或命名管道的 writer，
+// 不要求创建时已有进程监听该套接字或打开管道的读端；Sync 时等待缓冲的记录发送完
+func (c LogConfig) NewSocketWriter() (io.WriteCloser, error) {
+	if c.SocketPath == "" {
+		return nil, errors.New("未配置日志套接字路径")
+	}
+	network := "unix"
+	switch c.SocketType {
+	case "", SocketStream:
+	case SocketDatagram:
+		network = "unixgram"
+	case SocketPipe:
+		network = SocketPipe
+	default:
+		return nil, fmt.Errorf("未知的套接字类型: %q", c.SocketType)
+	}
+	max := c.SocketBuffer
+	if max <= 0 {
+		max = defaultSocketBuffer
+	}
+	w := &socketWriter{network: network, path: c.SocketPath, max: max, done: make(chan struct{})}
+	w.cond = sync.NewCond(&w.mu)
+	go w.run()
+	if old := activeSocket.Swap(w); old != nil {
+		old.Flush(socketFlushTimeout)
+		old.Close()
+	}
+	return w, nil
+}
+
+func (w *socketWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, errSocketClosed
+	}
+	if len(w.queue) >= w.max {
+		return 0, errSocketBufferFull
+	}
+	w.queue = append(w.queue, append([]byte(nil), p...))
+	w.cond.Broadcast()
+	return len(p), nil
+}
+
+// run 按顺序发送缓冲区中的记录，发送成功后才移出缓冲区
+func (w *socketWriter) run() {
+	backoff := socketMinBackoff
+	attempts := 0
+	for {
+		rec, ok := w.next()
+		if !ok {
+			if w.conn != nil {
+				w.conn.Close()
+			}
+			return
+		}
+		if w.conn == nil {
+			conn, err := w.dial()
+			if err != nil {
+				w.sleep(backoff)
+				backoff = min(backoff*2, socketMaxBackoff)
+				continue
+			}
+			w.conn = conn
+			backoff = socketMinBackoff
+		}
+		w.conn.SetWriteDeadline(time.Now().Add(socketWriteTimeout))
+		if _, err := w.conn.Write(rec); err != nil {
+			w.conn.Close()
+			w.conn = nil
+			if attempts++; attempts < socketMaxAttempts {
+				continue
+			}
+			CountDropped(SinkSocket, 1)
+		}
+		attempts = 0
+		w.pop()
+	}
+}
+
+// dial 连接套接字或打开命名管道
+func (w *socketWriter) dial() (socketConn, error) {
+	if w.network == SocketPipe {
+		return openPipe(w.path)
+	}
+	return net.DialTimeout(w.network, w.path, socketDialTimeout)
+}
+
+// next 等待并返回缓冲区中的第一条记录，关闭后返回 false
+func (w *socketWriter) next() ([]byte, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for len(w.queue) == 0 && !w.closed {
+		w.cond.Wait()
+	}
+	if len(w.queue) == 0 {
+		return nil, false
+	}
+	return w.queue[0], true
+}
+
+func (w *socketWriter) pop() {
+	w.mu.Lock()
+	if len(w.queue) > 0 { // 可能已被 Close 清空
+		w.queue[0] = nil
+		w.queue = w.queue[1:]
+	}
+	w.cond.Broadcast()
+	w.mu.Unlock()
+}
+
+// sleep 等待重连，关闭时立即返回
+func (w *socketWriter) sleep(d time.Duration) {
+	select {
+	case <-time.After(d):
+	case <-w.done:
+	}
+}
+
+// Flush 等待缓冲区中的记录发送完，超时返回错误
+func (w *socketWriter) Flush(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		w.mu.Lock()
+		n := len(w.queue)
+		w.mu.Unlock()
+		if n == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("日志套接字仍有 %d 条记录未发送", n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Close 停止发送，缓冲区中未发送的记录被丢弃
+func (w *socketWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if n := len(w.queue); n > 0 {
+		CountDropped(SinkSocket, n)
+		w.queue = nil
+	}
+	close(w.done)
+	w.cond.Broadcast()
+	return nil
+}
+
+// flushSocket 刷新当前的套接字输出，由 Sync 调用
+func flushSocket() error {
+	if w := activeSocket.Load(); w != nil {
+		return w.Flush(socketFlushTimeout)
+	}
+	return nil
+}
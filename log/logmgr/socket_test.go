@@ -0,0 +1,182 @@
+package logmgr
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// socketPath 返回临时目录下的套接字路径，Unix 套接字路径长度有限，不使用 t.TempDir
+func socketPath(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "sock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return filepath.Join(dir, "log.sock")
+}
+
+// readLines 从 r 中读取 n 行
+func readLines(t *testing.T, r *bufio.Reader, n int) []string {
+	t.Helper()
+	var lines []string
+	for len(lines) < n {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("已读取 %q: %v", lines, err)
+		}
+		lines = append(lines, strings.TrimSuffix(line, "\n"))
+	}
+	return lines
+}
+
+func TestSocketWriterReconnect(t *testing.T) {
+	path := socketPath(t)
+	w, err := LogConfig{SocketPath: path}.NewSocketWriter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// 还没有进程监听时记录保存在缓冲区中
+	w.Write([]byte("a\n"))
+	w.Write([]byte("b\n"))
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	ln.(*net.UnixListener).SetDeadline(time.Now().Add(5 * time.Second))
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readLines(t, bufio.NewReader(conn), 2); strings.Join(got, ",") != "a,b" {
+		t.Fatalf("收到 %q", got)
+	}
+
+	// 连接断开后重连，之后的记录发送到新连接
+	conn.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if c, err := ln.Accept(); err == nil {
+			accepted <- c
+		}
+	}()
+	// 写入已断开的连接可能不会立即失败，持续写入直到重连
+	deadline := time.Now().Add(5 * time.Second)
+	var conn2 net.Conn
+	for conn2 == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("没有重连")
+		}
+		w.Write([]byte("c\n"))
+		select {
+		case conn2 = <-accepted:
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+	r := bufio.NewReader(conn2)
+	defer conn2.Close()
+	if got := readLines(t, r, 1); got[0] != "c" {
+		t.Fatalf("重连后收到 %q", got)
+	}
+}
+
+func TestSocketWriterBuffer(t *testing.T) {
+	w, err := LogConfig{SocketPath: socketPath(t), SocketBuffer: 2}.NewSocketWriter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	for i, want := range []error{nil, nil, errSocketBufferFull} {
+		if _, err := w.Write([]byte("x\n")); !errors.Is(err, want) {
+			t.Fatalf("第 %d 次写入返回 %v，期望 %v", i+1, err, want)
+		}
+	}
+	if err := w.(*socketWriter).Flush(50 * time.Millisecond); err == nil {
+		t.Fatal("没有监听时 Flush 应超时")
+	}
+	w.Close()
+	if _, err := w.Write([]byte("x\n")); !errors.Is(err, errSocketClosed) {
+		t.Fatalf("关闭后写入返回 %v", err)
+	}
+}
+
+func TestSocketWriterDatagram(t *testing.T) {
+	path := socketPath(t)
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	w, err := LogConfig{SocketPath: path, SocketType: SocketDatagram}.NewSocketWriter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	w.Write([]byte("first\n"))
+	w.Write([]byte("second\n"))
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 64)
+	for _, want := range []string{"first\n", "second\n"} {
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(buf[:n]); got != want {
+			t.Fatalf("数据报 %q，期望 %q", got, want)
+		}
+	}
+}
+
+func TestNewSocketWriterErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config LogConfig
+		want   string
+	}{
+		{name: "no path", config: LogConfig{}, want: "未配置"},
+		{name: "type", config: LogConfig{SocketPath: "x", SocketType: "tcp"}, want: "未知的套接字类型"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.config.NewSocketWriter(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("错误 %v，期望包含 %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutputSinks(t *testing.T) {
+	tests := []struct {
+		output  string
+		console bool
+		sink    string
+	}{
+		{output: "console", console: true},
+		{output: "file", sink: SinkFile},
+		{output: "both", console: true, sink: SinkFile},
+		{output: "memory", sink: SinkMemory},
+		{output: "socket", sink: SinkSocket},
+		{output: "console+socket", console: true, sink: SinkSocket},
+		{output: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			c := LogConfig{Output: tt.output}
+			if c.ConsoleEnabled() != tt.console || c.GetSink() != tt.sink {
+				t.Fatalf("控制台 %v，输出 %q", c.ConsoleEnabled(), c.GetSink())
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+//go:build unix
+
+package logmgr
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestSocketWriterPipe(t *testing.T) {
+	path := socketPath(t)
+	if err := syscall.Mkfifo(path, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	w, err := LogConfig{SocketPath: path, SocketType: SocketPipe}.NewSocketWriter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// 没有进程打开读端时记录保存在缓冲区中
+	w.Write([]byte("a\n"))
+	r, err := os.OpenFile(path, os.O_RDONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	w.Write([]byte("b\n"))
+
+	r.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if got := readLines(t, bufio.NewReader(r), 2); strings.Join(got, ",") != "a,b" {
+		t.Fatalf("收到 %q", got)
+	}
+}
+
+func TestSocketWriterPipeNotFifo(t *testing.T) {
+	path := socketPath(t)
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := openPipe(path); err == nil || !strings.Contains(err.Error(), "不是命名管道") {
+		t.Fatalf("普通文件返回 %v", err)
+	}
+}
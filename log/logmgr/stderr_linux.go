@@ -0,0 +1,23 @@
+//go:build linux
+
+package logmgr
+
+import (
+	"os"
+	"syscall"
+)
+
+// RedirectStderr 通过 dup2 将进程的标准错误重定向到 path，
+// 运行时致命错误(如 concurrent map writes)和未捕获的 panic 也会写入该文件
+//
+// 新建的文件使用默认权限 0600，配置 LogConfig.RedirectStderr 时由 NewFileWriter 按 FileMode 等配置打开。
+// 日志轮转后标准错误仍写入轮转前的文件。
+func RedirectStderr(path string) error {
+	return redirectStderr(defaultFilePerm(), path)
+}
+
+// dupStderr 将标准错误指向 f
+func dupStderr(f *os.File) error {
+	// 部分架构(如 arm64)没有 dup2 系统调用，使用 dup3
+	return syscall.Dup3(int(f.Fd()), int(os.Stderr.Fd()), 0)
+}
@@ -0,0 +1,57 @@
+//go:build linux
+
+package logmgr
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestRedirectStderr(t *testing.T) {
+	tests := []struct {
+		name   string
+		config LogConfig
+		mode   os.FileMode
+	}{
+		{name: "default", mode: defaultFileMode},
+		{name: "file mode", config: LogConfig{FileMode: "0640"}, mode: 0640},
+		{name: "multi process", config: LogConfig{FileMode: "0604", MultiProcess: true}, mode: 0604},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 测试结束后恢复标准错误
+			saved, err := syscall.Dup(int(os.Stderr.Fd()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() {
+				syscall.Dup3(saved, int(os.Stderr.Fd()), 0)
+				syscall.Close(saved)
+			}()
+
+			c := tt.config
+			c.FilePath = filepath.Join(t.TempDir(), "app.log")
+			c.RedirectStderr = true
+			w, err := c.NewFileWriter()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer w.(interface{ Close() error }).Close()
+
+			w.Write([]byte("log line\n"))
+			os.Stderr.WriteString("stderr line\n")
+
+			data, err := os.ReadFile(c.FilePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(data), "log line\n") || !strings.Contains(string(data), "stderr line\n") {
+				t.Fatalf("日志文件内容 %q", data)
+			}
+			assertMode(t, c.FilePath, tt.mode)
+		})
+	}
+}
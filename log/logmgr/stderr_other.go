@@ -0,0 +1,21 @@
+//go:build !linux
+
+package logmgr
+
+import (
+	"os"
+	"runtime/debug"
+)
+
+// RedirectStderr 将运行时致命错误和未捕获的 panic 输出到 path
+//
+// 非 Linux 平台无法重定向标准错误本身，使用 debug.SetCrashOutput 捕获崩溃信息。
+// 新建的文件使用默认权限 0600，配置 LogConfig.RedirectStderr 时由 NewFileWriter 按 FileMode 等配置打开。
+func RedirectStderr(path string) error {
+	return redirectStderr(defaultFilePerm(), path)
+}
+
+// dupStderr 将崩溃信息输出到 f
+func dupStderr(f *os.File) error {
+	return debug.SetCrashOutput(f, debug.CrashOptions{})
+}
@@ -0,0 +1,339 @@
+package logmgr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type colorMode uint8
+
+const (
+	colorNone colorMode = iota
+	color16
+	color256
+	colorRGB
+)
+
+// Color 终端颜色，支持基础 16 色、256 色和 24 位真彩色
+type Color struct {
+	mode  colorMode
+	value uint32
+}
+
+// 基础 16 色
+var (
+	NoColor       = Color{}
+	Black         = Basic(0)
+	Red           = Basic(1)
+	Green         = Basic(2)
+	Yellow        = Basic(3)
+	Blue          = Basic(4)
+	Magenta       = Basic(5)
+	Cyan          = Basic(6)
+	White         = Basic(7)
+	BrightBlack   = Basic(8)
+	BrightRed     = Basic(9)
+	BrightGreen   = Basic(10)
+	BrightYellow  = Basic(11)
+	BrightBlue    = Basic(12)
+	BrightMagenta = Basic(13)
+	BrightCyan    = Basic(14)
+	BrightWhite   = Basic(15)
+)
+
+var colorNames = map[string]Color{
+	"black": Black, "red": Red, "green": Green, "yellow": Yellow,
+	"blue": Blue, "magenta": Magenta, "cyan": Cyan, "white": White,
+	"bright_black": BrightBlack, "gray": BrightBlack, "grey": BrightBlack,
+	"bright_red": BrightRed, "bright_green": BrightGreen, "bright_yellow": BrightYellow,
+	"bright_blue": BrightBlue, "bright_magenta": BrightMagenta,
+	"bright_cyan": BrightCyan, "bright_white": BrightWhite,
+}
+
+// Basic 基础 16 色 (0-15)
+func Basic(n uint8) Color {
+	return Color{mode: color16, value: uint32(n & 0x0f)}
+}
+
+// Color256 256 色调色板 (0-255)
+func Color256(n uint8) Color {
+	return Color{mode: color256, value: uint32(n)}
+}
+
+// RGB 24 位真彩色
+func RGB(r, g, b uint8) Color {
+	return Color{mode: colorRGB, value: uint32(r)<<16 | uint32(g)<<8 | uint32(b)}
+}
+
+// ParseColor 解析颜色: 颜色名(red, bright_blue)、256 色编号(208) 或十六进制(#ff8800)
+func ParseColor(s string) (Color, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" || s == "none" || s == "default" {
+		return NoColor, nil
+	}
+	if c, ok := colorNames[s]; ok {
+		return c, nil
+	}
+	if strings.HasPrefix(s, "#") {
+		hex := s[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil || len(hex) != 6 {
+			return NoColor, fmt.Errorf("无效的颜色: %q", s)
+		}
+		return RGB(uint8(v>>16), uint8(v>>8), uint8(v)), nil
+	}
+	n, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return NoColor, fmt.Errorf("无效的颜色: %q", s)
+	}
+	return Color256(uint8(n)), nil
+}
+
+// appendParams 追加 SGR 参数，bg 表示背景色
+func (c Color) appendParams(params []string, bg bool) []string {
+	switch c.mode {
+	case color16:
+		base := 30
+		if bg {
+			base = 40
+		}
+		if c.value >= 8 {
+			base += 60
+		}
+		return append(params, strconv.Itoa(base+int(c.value&0x07)))
+	case color256:
+		prefix := "38;5;"
+		if bg {
+			prefix = "48;5;"
+		}
+		return append(params, prefix+strconv.Itoa(int(c.value)))
+	case colorRGB:
+		prefix := "38;2;"
+		if bg {
+			prefix = "48;2;"
+		}
+		return append(params, prefix+
+			strconv.Itoa(int(c.value>>16&0xff))+";"+
+			strconv.Itoa(int(c.value>>8&0xff))+";"+
+			strconv.Itoa(int(c.value&0xff)))
+	}
+	return params
+}
+
+// Style 单个元素的显示样式
+type Style struct {
+	Fg   Color // 前景色
+	Bg   Color // 背景色
+	Bold bool  // 加粗
+	Dim  bool  // 暗淡
+}
+
+// Sequence 返回样式对应的 ANSI 转义序列，无样式时返回空串
+func (s Style) Sequence() string {
+	var params []string
+	if s.Bold {
+		params = append(params, "1")
+	}
+	if s.Dim {
+		params = append(params, "2")
+	}
+	params = s.Fg.appendParams(params, false)
+	params = s.Bg.appendParams(params, true)
+	if len(params) == 0 {
+		return ""
+	}
+	return "\033[" + strings.Join(params, ";") + "m"
+}
+
+// Theme 控制台配色主题，每个元素一个样式
+type Theme struct {
+	Time    Style
+	Trace   Style
+	Debug   Style
+	Info    Style
+	Warn    Style
+	Error   Style
+	Panic   Style
+	Fatal   Style
+	Source  Style
+	Message Style
+	Key     Style // 字段名
+	Value   Style // 字段值
+}
+
+// Palette 预先计算好的主题转义序列，供编码器在热路径上直接拼接
+type Palette struct {
+	Time    string
+	Trace   string
+	Debug   string
+	Info    string
+	Warn    string
+	Error   string
+	Panic   string
+	Fatal   string
+	Source  string
+	Message string
+	Key     string
+	Value   string
+	Reset   string
+}
+
+// Palette 计算主题的转义序列
+func (t Theme) Palette() Palette {
+	p := Palette{
+		Time:    t.Time.Sequence(),
+		Trace:   t.Trace.Sequence(),
+		Debug:   t.Debug.Sequence(),
+		Info:    t.Info.Sequence(),
+		Warn:    t.Warn.Sequence(),
+		Error:   t.Error.Sequence(),
+		Panic:   t.Panic.Sequence(),
+		Fatal:   t.Fatal.Sequence(),
+		Source:  t.Source.Sequence(),
+		Message: t.Message.Sequence(),
+		Key:     t.Key.Sequence(),
+		Value:   t.Value.Sequence(),
+	}
+	if p.Time+p.Trace+p.Debug+p.Info+p.Warn+p.Error+p.Panic+p.Fatal+p.Source+p.Message+p.Key+p.Value != "" {
+		p.Reset = ColorReset
+	}
+	return p
+}
+
+// Level 返回级别对应的转义序列
+func (p Palette) Level(level Level) string {
+	switch level {
+	case TraceLevel:
+		return p.Trace
+	case DebugLevel:
+		return p.Debug
+	case InfoLevel:
+		return p.Info
+	case WarnLevel:
+		return p.Warn
+	case ErrorLevel:
+		return p.Error
+	case PanicLevel:
+		return p.Panic
+	case FatalLevel:
+		return p.Fatal
+	default:
+		return ""
+	}
+}
+
+// Paint 使用转义序列包裹文本
+func (p Palette) Paint(seq, text string) string {
+	if seq == "" {
+		return text
+	}
+	return seq + text + p.Reset
+}
+
+// appendReset 在使用了 seq 样式时追加重置序列
+func (p Palette) appendReset(buf []byte, seq string) []byte {
+	if seq == "" {
+		return buf
+	}
+	return append(buf, p.Reset...)
+}
+
+// 内置主题
+var (
+	// DefaultTheme 默认主题，与 Color* 常量一致
+	DefaultTheme = Theme{
+		Time:    Style{Fg: Magenta},
+		Trace:   Style{Fg: BrightBlack},
+		Debug:   Style{Fg: Cyan},
+		Info:    Style{Fg: Green},
+		Warn:    Style{Fg: Yellow},
+		Error:   Style{Fg: Red},
+		Panic:   Style{Fg: Red, Bold: true},
+		Fatal:   Style{Fg: BrightWhite, Bg: Red, Bold: true},
+		Source:  Style{Fg: Blue},
+		Message: Style{Fg: White},
+		Key:     Style{Fg: White},
+	}
+
+	// DarkTheme 适用于深色背景的 256 色主题
+	DarkTheme = Theme{
+		Time:    Style{Fg: Color256(245)},
+		Trace:   Style{Fg: Color256(240)},
+		Debug:   Style{Fg: Color256(109)},
+		Info:    Style{Fg: Color256(114)},
+		Warn:    Style{Fg: Color256(221), Bold: true},
+		Error:   Style{Fg: Color256(203), Bold: true},
+		Panic:   Style{Fg: Color256(197), Bold: true},
+		Fatal:   Style{Fg: Color256(231), Bg: Color256(160), Bold: true},
+		Source:  Style{Fg: Color256(139)},
+		Message: Style{Fg: Color256(252)},
+		Key:     Style{Fg: Color256(110)},
+		Value:   Style{Fg: Color256(250)},
+	}
+
+	// LightTheme 适用于浅色背景的 256 色主题
+	LightTheme = Theme{
+		Time:    Style{Fg: Color256(242)},
+		Trace:   Style{Fg: Color256(246)},
+		Debug:   Style{Fg: Color256(30)},
+		Info:    Style{Fg: Color256(28)},
+		Warn:    Style{Fg: Color256(130), Bold: true},
+		Error:   Style{Fg: Color256(160), Bold: true},
+		Panic:   Style{Fg: Color256(125), Bold: true},
+		Fatal:   Style{Fg: Color256(231), Bg: Color256(124), Bold: true},
+		Source:  Style{Fg: Color256(90)},
+		Message: Style{Fg: Color256(235)},
+		Key:     Style{Fg: Color256(24)},
+		Value:   Style{Fg: Color256(238)},
+	}
+
+	// MonochromeTheme 无颜色，仅使用加粗/暗淡区分
+	MonochromeTheme = Theme{
+		Time:  Style{Dim: true},
+		Trace: Style{Dim: true},
+		Debug: Style{Dim: true},
+		Warn:  Style{Bold: true},
+		Error: Style{Bold: true},
+		Panic: Style{Bold: true},
+		Fatal: Style{Bold: true},
+		Key:   Style{Dim: true},
+	}
+)
+
+var (
+	themeMu sync.RWMutex
+	themes  = map[string]Theme{
+		"default":    DefaultTheme,
+		"dark":       DarkTheme,
+		"light":      LightTheme,
+		"monochrome": MonochromeTheme,
+	}
+)
+
+// RegisterTheme 注册自定义主题，之后可在 LogConfig.Theme 中按名称引用
+func RegisterTheme(name string, theme Theme) {
+	themeMu.Lock()
+	defer themeMu.Unlock()
+	themes[strings.ToLower(name)] = theme
+}
+
+// LookupTheme 按名称查找主题
+func LookupTheme(name string) (Theme, bool) {
+	themeMu.RLock()
+	defer themeMu.RUnlock()
+	t, ok := themes[strings.ToLower(strings.TrimSpace(name))]
+	return t, ok
+}
+
+// GetTheme 返回配置的主题，未配置时返回默认主题；未知名称由 Validate 报告，Checked 恢复为默认主题
+func (c LogConfig) GetTheme() Theme {
+	if t, ok := LookupTheme(c.Theme); ok {
+		return t
+	}
+	return DefaultTheme
+}
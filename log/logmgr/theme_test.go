@@ -0,0 +1,43 @@
+package logmgr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateTheme(t *testing.T) {
+	custom := Theme{Message: Style{Bold: true}}
+	RegisterTheme("Custom-Test", custom)
+
+	tests := []struct {
+		theme   string
+		wantErr bool
+		want    Theme // Checked 之后 GetTheme 的结果
+	}{
+		{theme: "", want: DefaultTheme},
+		{theme: "dark", want: DarkTheme},
+		{theme: " Light ", want: LightTheme},
+		{theme: "MONOCHROME", want: MonochromeTheme},
+		{theme: "custom-test", want: custom},
+		{theme: "drak", wantErr: true, want: DefaultTheme},
+	}
+	for _, tt := range tests {
+		t.Run(tt.theme, func(t *testing.T) {
+			c := LogConfig{Theme: tt.theme}
+			err := c.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v，期望错误: %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.theme) {
+				t.Fatalf("错误信息未包含主题名称: %v", err)
+			}
+			c = c.Checked()
+			if tt.wantErr && c.Theme != "" {
+				t.Fatalf("Checked() 保留了未知主题 %q", c.Theme)
+			}
+			if c.GetTheme() != tt.want {
+				t.Fatalf("GetTheme() = %+v，期望 %+v", c.GetTheme(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package logmgr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 预定义的时间格式
+const (
+	TimeFormatRFC3339     = "rfc3339"
+	TimeFormatRFC3339Nano = "rfc3339nano"
+	TimeFormatUnix        = "unix"   // 秒
+	TimeFormatUnixMs      = "unixms" // 毫秒
+	TimeFormatUnixUs      = "unixus" // 微秒
+	TimeFormatUnixNs      = "unixns" // 纳秒
+)
+
+// TimeEncoder 按配置的格式和时区编码时间
+type TimeEncoder struct {
+	layout string        // 文本格式，数值格式时为空
+	unit   time.Duration // 数值格式的单位
+	loc    *time.Location
+}
+
+// NewTimeEncoder 解析时间格式和时区，format 为空时使用 DefaultTimeLayout，zone 为空时使用本地时区
+func NewTimeEncoder(format, zone string) (TimeEncoder, error) {
+	enc := TimeEncoder{loc: time.Local}
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "":
+		enc.layout = DefaultTimeLayout
+	case TimeFormatRFC3339:
+		enc.layout = time.RFC3339
+	case TimeFormatRFC3339Nano:
+		enc.layout = time.RFC3339Nano
+	case TimeFormatUnix:
+		enc.unit = time.Second
+	case TimeFormatUnixMs:
+		enc.unit = time.Millisecond
+	case TimeFormatUnixUs:
+		enc.unit = time.Microsecond
+	case TimeFormatUnixNs:
+		enc.unit = time.Nanosecond
+	default:
+		enc.layout = format
+	}
+
+	switch strings.ToLower(strings.TrimSpace(zone)) {
+	case "", "local":
+	case "utc":
+		enc.loc = time.UTC
+	default:
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			return TimeEncoder{}, fmt.Errorf("无效的时区 %q: %w", zone, err)
+		}
+		enc.loc = loc
+	}
+	return enc, nil
+}
+
+// NewTimeEncoder 按配置创建时间编码器，未配置 TimeFormat 时使用 Schema 的默认时间格式和时区
+func (c LogConfig) NewTimeEncoder() (TimeEncoder, error) {
+	format, zone := c.TimeFormat, c.TimeZone
+	if strings.TrimSpace(format) == "" {
+		s := c.GetSchema()
+		format = s.TimeFormat
+		if strings.TrimSpace(zone) == "" {
+			zone = s.TimeZone
+		}
+	}
+	return NewTimeEncoder(format, zone)
+}
+
+// Numeric 是否为 Unix 数值格式
+func (e TimeEncoder) Numeric() bool {
+	return e.unit != 0
+}
+
+// Layout 返回文本格式，数值格式时返回空串
+func (e TimeEncoder) Layout() string {
+	return e.layout
+}
+
+// Unit 返回数值格式的单位
+func (e TimeEncoder) Unit() time.Duration {
+	return e.unit
+}
+
+// Location 返回时区
+func (e TimeEncoder) Location() *time.Location {
+	if e.loc == nil {
+		return time.Local
+	}
+	return e.loc
+}
+
+// Int64 返回数值格式的时间戳
+func (e TimeEncoder) Int64(t time.Time) int64 {
+	if e.unit == 0 {
+		return t.Unix()
+	}
+	return t.UnixNano() / int64(e.unit)
+}
+
+// Format 将时间编码为文本，数值格式返回十进制数字
+func (e TimeEncoder) Format(t time.Time) string {
+	return string(e.AppendFormat(nil, t))
+}
+
+// AppendFormat 追加编码后的时间
+func (e TimeEncoder) AppendFormat(buf []byte, t time.Time) []byte {
+	if e.unit != 0 {
+		return strconv.AppendInt(buf, e.Int64(t), 10)
+	}
+	return t.In(e.Location()).AppendFormat(buf, e.layout)
+}
+
+// Parse 解析 Format 输出的时间
+func (e TimeEncoder) Parse(s string) (time.Time, error) {
+	if e.unit != 0 {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(0, n*int64(e.unit)).In(e.Location()), nil
+	}
+	return time.ParseInLocation(e.layout, s, e.Location())
+}
+
+// FieldKeys JSON/logfmt 输出中内置字段的键名
+type FieldKeys struct {
+	Time       string
+	Level      string
+	Message    string
+	Caller     string
+	Stacktrace string
+}
+
+// FieldKeys 返回内置字段名，依次使用 TimeKey 等单独配置的名称、Schema 的名称和后端原生名称 native
+func (c LogConfig) FieldKeys(native FieldKeys) FieldKeys {
+	keys := native
+	keys.override(c.GetSchema().Keys)
+	keys.override(FieldKeys{Time: c.TimeKey, Level: c.LevelKey, Message: c.MessageKey, Caller: c.CallerKey})
+	return keys
+}
+
+// override 使用 o 中不为空的字段名
+func (k *FieldKeys) override(o FieldKeys) {
+	if o.Time != "" {
+		k.Time = o.Time
+	}
+	if o.Level != "" {
+		k.Level = o.Level
+	}
+	if o.Message != "" {
+		k.Message = o.Message
+	}
+	if o.Caller != "" {
+		k.Caller = o.Caller
+	}
+	if o.Stacktrace != "" {
+		k.Stacktrace = o.Stacktrace
+	}
+}
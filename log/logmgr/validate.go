@@ -0,0 +1,119 @@
+package logmgr
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Validate 检查配置，返回所有无效配置项的错误
+func (c LogConfig) Validate() error {
+	_, errs := c.check()
+	return errors.Join(errs...)
+}
+
+// Checked 返回检查后的配置，由各管理器的 Setup 调用: 无效的配置项输出到标准错误并恢复为默认值，
+// 配置错误不会导致进程退出。需要在配置错误时启动失败的程序可先调用 Validate。
+func (c LogConfig) Checked() LogConfig {
+	c, errs := c.check()
+	for _, err := range errs {
+		fmt.Fprintf(os.Stderr, "日志配置无效，使用默认值: %v\n", err)
+	}
+	return c
+}
+
+// check 返回将无效配置项恢复为默认值后的配置，以及每一项的错误
+func (c LogConfig) check() (LogConfig, []error) {
+	var errs []error
+	if _, err := ParseLevel(c.Level); err != nil {
+		errs = append(errs, err)
+		c.Level = ""
+	}
+	switch strings.ToLower(strings.TrimSpace(c.StacktraceLevel)) {
+	case "", "none", "off":
+	default:
+		if _, err := ParseLevel(c.StacktraceLevel); err != nil {
+			errs = append(errs, fmt.Errorf("堆栈级别无效: %w", err))
+			c.StacktraceLevel = ""
+		}
+	}
+	if strings.TrimSpace(c.FlightRecorderLevel) != "" {
+		if _, err := ParseLevel(c.FlightRecorderLevel); err != nil {
+			errs = append(errs, fmt.Errorf("飞行记录器级别无效: %w", err))
+			c.FlightRecorderLevel = ""
+		}
+	}
+	if strings.TrimSpace(c.Schema) != "" {
+		if _, ok := LookupSchema(c.Schema); !ok {
+			errs = append(errs, fmt.Errorf("未知的字段映射: %s", c.Schema))
+			c.Schema = ""
+		}
+	}
+	if _, err := NewTimeEncoder("", c.TimeZone); err != nil {
+		errs = append(errs, err)
+		c.TimeZone = ""
+	}
+	if c.Theme != "" {
+		if _, ok := LookupTheme(c.Theme); !ok {
+			errs = append(errs, fmt.Errorf("未知的主题: %s", c.Theme))
+			c.Theme = ""
+		}
+	}
+	if _, err := NewFormatter(c.Template, DefaultTheme); err != nil {
+		errs = append(errs, err)
+		c.Template = ""
+	}
+	switch c.CallerFormat {
+	case "", CallerShort, CallerFull, CallerFunction:
+	default:
+		errs = append(errs, fmt.Errorf("未知的调用位置格式: %q", c.CallerFormat))
+		c.CallerFormat = ""
+	}
+	switch c.ConsoleEncoding {
+	case "", EncodingText, EncodingJSON, EncodingLogfmt:
+	default:
+		errs = append(errs, fmt.Errorf("未知的控制台编码: %q", c.ConsoleEncoding))
+		c.ConsoleEncoding = ""
+	}
+	switch c.FileEncoding {
+	case "", EncodingJSON, EncodingLogfmt:
+	default:
+		errs = append(errs, fmt.Errorf("文件编码只能是 json 或 logfmt: %q", c.FileEncoding))
+		c.FileEncoding = ""
+	}
+	switch c.SocketType {
+	case "", SocketStream, SocketDatagram, SocketPipe:
+	default:
+		errs = append(errs, fmt.Errorf("未知的套接字类型: %q", c.SocketType))
+		c.SocketType = ""
+	}
+	if _, err := c.codec(); err != nil {
+		errs = append(errs, err)
+		if _, err := (LogConfig{CompressCodec: c.CompressCodec}).codec(); err != nil {
+			c.CompressCodec = ""
+		}
+		c.CompressLevel = 0
+	}
+	if c.DirMode != "" {
+		if _, err := parseFileMode(c.DirMode); err != nil {
+			errs = append(errs, fmt.Errorf("日志目录权限无效: %w", err))
+			c.DirMode = ""
+		}
+	}
+	if c.FileMode != "" {
+		if _, err := parseFileMode(c.FileMode); err != nil {
+			errs = append(errs, fmt.Errorf("日志文件权限无效: %w", err))
+			c.FileMode = ""
+		}
+	}
+	if c.Encrypted() {
+		switch c.FallbackOutput {
+		case "stderr", "stdout", "console":
+			// 备用输出不加密，会泄露加密日志的内容
+			errs = append(errs, fmt.Errorf("加密日志文件时备用输出只能是文件: %s", c.FallbackOutput))
+			c.FallbackOutput = ""
+		}
+	}
+	return c, errs
+}
@@ -0,0 +1,67 @@
+package logmgr
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config LogConfig
+		errMsg string    // 错误信息包含的内容，为空表示没有错误
+		want   LogConfig // Checked 之后的配置
+	}{
+		{
+			name:   "valid",
+			config: LogConfig{CallerFormat: CallerFunction, ConsoleEncoding: EncodingLogfmt, FileEncoding: EncodingJSON, SocketType: SocketPipe, CompressCodec: CodecZstd, CompressLevel: 19, DirMode: "0750", FileMode: "640"},
+			want:   LogConfig{CallerFormat: CallerFunction, ConsoleEncoding: EncodingLogfmt, FileEncoding: EncodingJSON, SocketType: SocketPipe, CompressCodec: CodecZstd, CompressLevel: 19, DirMode: "0750", FileMode: "640"},
+		},
+		{name: "caller format", config: LogConfig{CallerFormat: "long"}, errMsg: "调用位置格式"},
+		{name: "console encoding", config: LogConfig{ConsoleEncoding: "yaml"}, errMsg: "控制台编码"},
+		{name: "file encoding", config: LogConfig{FileEncoding: EncodingText}, errMsg: "文件编码"},
+		{name: "socket type", config: LogConfig{SocketType: "tcp"}, errMsg: "套接字类型"},
+		{name: "codec", config: LogConfig{CompressCodec: "lz4", CompressLevel: 3}, errMsg: "压缩方式"},
+		{
+			// 只恢复无效的压缩级别
+			name:   "gzip level",
+			config: LogConfig{CompressCodec: CodecGzip, CompressLevel: 12},
+			errMsg: "gzip 压缩级别",
+			want:   LogConfig{CompressCodec: CodecGzip},
+		},
+		{
+			name:   "zstd level",
+			config: LogConfig{CompressCodec: CodecZstd, CompressLevel: -1},
+			errMsg: "zstd 压缩级别",
+			want:   LogConfig{CompressCodec: CodecZstd},
+		},
+		{name: "dir mode", config: LogConfig{DirMode: "rwx"}, errMsg: "日志目录权限"},
+		{name: "file mode", config: LogConfig{FileMode: "01777"}, errMsg: "日志文件权限"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if (err != nil) != (tt.errMsg != "") {
+				t.Fatalf("Validate() = %v，期望错误: %q", err, tt.errMsg)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.errMsg) {
+				t.Fatalf("错误信息: %v", err)
+			}
+			checked := tt.config.Checked()
+			if !reflect.DeepEqual(checked, tt.want) {
+				t.Fatalf("Checked() = %+v，期望 %+v", checked, tt.want)
+			}
+			// 检查后的配置不会在 Setup 中失败
+			if err := checked.Validate(); err != nil {
+				t.Fatalf("Checked().Validate() = %v", err)
+			}
+			if _, err := checked.codec(); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := checked.filePerm(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
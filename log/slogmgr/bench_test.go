@@ -0,0 +1,113 @@
+package slogmgr
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/52debug/go-box/log/logmgr"
+)
+
+const benchMessage = "请求完成"
+
+// benchmarkOutputs 在控制台(写入空设备)和文件输出下记录日志，disabled 记录低于日志级别的日志
+func benchmarkOutputs(b *testing.B, setup func(logmgr.LogConfig)) {
+	for _, output := range []string{"console", "file", "disabled"} {
+		b.Run(output, func(b *testing.B) {
+			null, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+			if err != nil {
+				b.Fatal(err)
+			}
+			stdout := os.Stdout
+			os.Stdout = null
+			defer func() {
+				os.Stdout = stdout
+				null.Close()
+			}()
+
+			config := logmgr.LogConfig{Level: "info", Output: output, FilePath: filepath.Join(b.TempDir(), "bench.log")}
+			level := slog.LevelInfo
+			if output == "disabled" {
+				config.Output = "file"
+				level = slog.LevelDebug
+			}
+			setup(config)
+			logger := slog.Default()
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				logger.Log(ctx, level, benchMessage, "method", "GET", "status", 200,
+					"latency", 1500*time.Microsecond, "request_id", int64(i))
+			}
+			b.StopTimer()
+			logmgr.Sync()
+		})
+	}
+}
+
+func BenchmarkSetup(b *testing.B) {
+	benchmarkOutputs(b, Setup)
+}
+
+func BenchmarkSetupWithColor(b *testing.B) {
+	benchmarkOutputs(b, SetupWithColor)
+}
+
+// newBenchRecord 返回带有各类字段的记录
+func newBenchRecord() slog.Record {
+	var pcs [1]uintptr
+	runtime.Callers(1, pcs[:])
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, benchMessage, pcs[0])
+	r.AddAttrs(
+		slog.String("method", "GET"),
+		slog.Int("status", 200),
+		slog.Duration("latency", 1500*time.Microsecond),
+		slog.Float64("ratio", 0.25),
+		slog.Bool("cached", true),
+		slog.Time("at", time.Now()),
+		slog.Any("err", errors.New("timeout")),
+	)
+	return r
+}
+
+func newBenchColorHandler(tb testing.TB) slog.Handler {
+	formatter, err := logmgr.LogConfig{}.NewFormatter()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return newColorHandler(io.Discard, formatter, slog.LevelInfo, true).
+		WithAttrs([]slog.Attr{slog.String("service", "api")}).
+		WithGroup("req")
+}
+
+func BenchmarkColorHandler(b *testing.B) {
+	h := newBenchColorHandler(b)
+	r := newBenchRecord()
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.Handle(ctx, r)
+	}
+}
+
+func TestColorHandlerAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("race 模式下分配次数不稳定")
+	}
+	h := newBenchColorHandler(t)
+	r := newBenchRecord()
+	ctx := context.Background()
+	h.Handle(ctx, r) // 预热缓冲池和调用位置缓存
+	if allocs := testing.AllocsPerRun(100, func() { h.Handle(ctx, r) }); allocs > 0 {
+		t.Fatalf("每条日志分配 %v 次", allocs)
+	}
+}
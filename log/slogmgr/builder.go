@@ -0,0 +1,156 @@
+package slogmgr
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"time"
+
+	"github.com/52debug/go-box/log/logmgr"
+)
+
+// Builder 以类型化方法添加字段的 slog 日志构建器，由 From 创建
+//
+//	slogmgr.From(ctx).Str("user", name).Int("n", 1).Err(err).Info("保存失败")
+//
+// 字段以 slog.Attr 保存，基本类型不装箱为 any。每次 From 创建新的 Builder，
+// 不能在多个 goroutine 中同时使用。
+type Builder struct {
+	ctx    context.Context
+	logger *slog.Logger
+	attrs  []slog.Attr
+	buf    [8]slog.Attr // attrs 的初始存储，字段不多时无需再次分配
+}
+
+// From 返回使用 ctx 中 logger(见 logmgr.WithLogger，没有时为 slog.Default())的构建器，
+// 记录日志时 ctx 传给处理器
+func From(ctx context.Context) *Builder {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	b := &Builder{ctx: ctx, logger: logmgr.FromContext(ctx)}
+	b.attrs = b.buf[:0]
+	return b
+}
+
+// Str 添加字符串字段
+func (b *Builder) Str(key, value string) *Builder {
+	b.attrs = append(b.attrs, slog.String(key, value))
+	return b
+}
+
+// Int 添加整数字段
+func (b *Builder) Int(key string, value int) *Builder {
+	b.attrs = append(b.attrs, slog.Int(key, value))
+	return b
+}
+
+// Int64 添加 int64 字段
+func (b *Builder) Int64(key string, value int64) *Builder {
+	b.attrs = append(b.attrs, slog.Int64(key, value))
+	return b
+}
+
+// Uint64 添加 uint64 字段
+func (b *Builder) Uint64(key string, value uint64) *Builder {
+	b.attrs = append(b.attrs, slog.Uint64(key, value))
+	return b
+}
+
+// Float64 添加浮点数字段
+func (b *Builder) Float64(key string, value float64) *Builder {
+	b.attrs = append(b.attrs, slog.Float64(key, value))
+	return b
+}
+
+// Bool 添加布尔字段
+func (b *Builder) Bool(key string, value bool) *Builder {
+	b.attrs = append(b.attrs, slog.Bool(key, value))
+	return b
+}
+
+// Dur 添加时长字段
+func (b *Builder) Dur(key string, value time.Duration) *Builder {
+	b.attrs = append(b.attrs, slog.Duration(key, value))
+	return b
+}
+
+// Time 添加时间字段
+func (b *Builder) Time(key string, value time.Time) *Builder {
+	b.attrs = append(b.attrs, slog.Time(key, value))
+	return b
+}
+
+// Err 添加结构化的 error 字段(见 logmgr.Err)，err 为 nil 时忽略
+func (b *Builder) Err(err error) *Builder {
+	if err != nil {
+		b.attrs = append(b.attrs, slog.Any("error", logmgr.Err(err)))
+	}
+	return b
+}
+
+// Any 添加任意类型的字段
+func (b *Builder) Any(key string, value interface{}) *Builder {
+	b.attrs = append(b.attrs, slog.Any(key, value))
+	return b
+}
+
+// Attrs 添加 slog.Attr 字段，如 slog.Group
+func (b *Builder) Attrs(attrs ...slog.Attr) *Builder {
+	b.attrs = append(b.attrs, attrs...)
+	return b
+}
+
+// Debug 记录 debug 日志
+func (b *Builder) Debug(msg string) {
+	b.log(slog.LevelDebug, msg)
+}
+
+// Info 记录 info 日志
+func (b *Builder) Info(msg string) {
+	b.log(slog.LevelInfo, msg)
+}
+
+// Warn 记录 warn 日志
+func (b *Builder) Warn(msg string) {
+	b.log(slog.LevelWarn, msg)
+}
+
+// Error 记录 error 日志
+func (b *Builder) Error(msg string) {
+	b.log(slog.LevelError, msg)
+}
+
+// Log 记录指定级别的日志
+func (b *Builder) Log(level slog.Level, msg string) {
+	b.log(level, msg)
+}
+
+// log 由导出的记录方法调用，调用位置为调用导出方法的位置。级别未开启时不创建记录，字段值不会被求值
+func (b *Builder) log(level slog.Level, msg string) {
+	h := b.logger.Handler()
+	if !h.Enabled(b.ctx, level) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.AddAttrs(b.attrs...)
+	_ = h.Handle(b.ctx, r)
+}
+
+// Logger 返回带有已添加字段的 logger
+func (b *Builder) Logger() *slog.Logger {
+	if len(b.attrs) == 0 {
+		return b.logger
+	}
+	return slog.New(b.logger.Handler().WithAttrs(b.attrs))
+}
+
+// Context 返回携带 Logger() 的 context，之后通过 From 取得的构建器包含已添加的字段
+//
+//	ctx = slogmgr.From(ctx).Str("request_id", id).Context()
+func (b *Builder) Context() context.Context {
+	ctx := b.ctx
+	return logmgr.WithLogger(ctx, b.Logger())
+}
@@ -0,0 +1,155 @@
+package slogmgr
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/52debug/go-box/log/logmgr"
+)
+
+// setupBuilder 输出 info 及以上级别到捕获
+func setupBuilder(t *testing.T) *logmgr.Capture {
+	t.Helper()
+	c, capture := logmgr.Test()
+	c.Level = "info"
+	Setup(c)
+	return capture
+}
+
+func TestBuilderFields(t *testing.T) {
+	capture := setupBuilder(t)
+	From(context.Background()).
+		Str("s", "v").Int("i", 1).Int64("i64", -2).Uint64("u", 3).Float64("f", 1.5).Bool("b", true).
+		Dur("d", time.Second).Time("t", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)).
+		Err(errors.New("boom")).Err(nil).
+		Any("a", []int{1}).Attrs(slog.Group("g", slog.String("k", "v"))).
+		Warn("msg")
+
+	logs := capture.Logs()
+	if len(logs) != 1 {
+		t.Fatalf("输出 %d 条日志", len(logs))
+	}
+	// 字段按添加顺序输出，类型与方法一致，Err(nil) 被忽略
+	want := `"msg":"msg","s":"v","i":1,"i64":-2,"u":3,"f":1.5,"b":true,"d":1000000000,"t":"2024-01-02T03:04:05Z",` +
+		`"error":{"msg":"boom","type":"*errors.errorString"},"a":[1],"g":{"k":"v"}}`
+	if !strings.HasSuffix(logs[0], want) || !strings.Contains(logs[0], `"level":"warn"`) {
+		t.Fatalf("输出 %s", logs[0])
+	}
+	// 调用位置为调用 Warn 的位置
+	if e := capture.Entries()[0]; !strings.HasPrefix(e.Caller, "slogmgr/builder_test.go:") {
+		t.Fatalf("调用位置 %q", e.Caller)
+	}
+}
+
+// countingValuer 记录 LogValue 被调用的次数
+type countingValuer struct {
+	n *int
+}
+
+func (v countingValuer) LogValue() slog.Value {
+	*v.n++
+	return slog.StringValue("v")
+}
+
+func TestBuilderLevel(t *testing.T) {
+	capture := setupBuilder(t)
+	var n int
+	v := countingValuer{n: &n}
+	ctx := context.Background()
+
+	// 级别未开启时不创建记录，字段值不会求值
+	From(ctx).Any("k", v).Debug("debug")
+	From(ctx).Any("k", v).Log(slog.LevelDebug-4, "trace")
+	if n != 0 || len(capture.Logs()) != 0 {
+		t.Fatalf("求值 %d 次，输出 %q", n, capture.Logs())
+	}
+
+	From(ctx).Any("k", v).Info("info")
+	From(ctx).Any("k", v).Log(slog.LevelError, "error")
+	if n != 2 {
+		t.Fatalf("求值 %d 次，期望 2", n)
+	}
+	entries := capture.Entries()
+	if len(entries) != 2 || entries[0].Level != logmgr.InfoLevel || entries[1].Level != logmgr.ErrorLevel {
+		t.Fatalf("输出 %q", capture.Logs())
+	}
+
+	// ctx 通过 WithDebug 开启 debug 时传给处理器
+	c, capture := logmgr.Test()
+	c.Level = "info"
+	c.ContextDebug = true
+	Setup(c)
+	From(logmgr.WithDebug(ctx)).Debug("debug")
+	if logs := capture.Logs(); len(logs) != 1 {
+		t.Fatalf("输出 %q", logs)
+	}
+}
+
+func TestBuilderContext(t *testing.T) {
+	capture := setupBuilder(t)
+
+	ctx := From(context.Background()).Str("request_id", "r1").Context()
+	// 之后的构建器使用 context 中缓存的 logger
+	if From(ctx).Logger() != logmgr.FromContext(ctx) {
+		t.Fatal("没有字段时 Logger 应返回 context 中的 logger")
+	}
+	From(ctx).Int("n", 1).Info("a")
+	From(ctx).Info("b")
+	// 嵌套的 context 包含两层字段
+	From(From(ctx).Str("user", "u1").Context()).Info("c")
+
+	want := []string{
+		`"msg":"a","request_id":"r1","n":1}`,
+		`"msg":"b","request_id":"r1"}`,
+		`"msg":"c","request_id":"r1","user":"u1"}`,
+	}
+	logs := capture.Logs()
+	if len(logs) != len(want) {
+		t.Fatalf("输出 %q", logs)
+	}
+	for i, w := range want {
+		if !strings.HasSuffix(logs[i], w) {
+			t.Fatalf("第 %d 条日志 %s，期望以 %s 结尾", i, logs[i], w)
+		}
+	}
+}
+
+func TestBuilderIndependent(t *testing.T) {
+	capture := setupBuilder(t)
+	ctx := context.Background()
+
+	// 每次 From 返回新的构建器，结束后继续使用不会影响其他构建器
+	b1 := From(ctx).Str("a", "1")
+	b1.Info("one")
+	b2 := From(ctx)
+	if b1 == b2 {
+		t.Fatal("From 返回了已结束的构建器")
+	}
+	b2.Str("b", "2").Info("two")
+	b1.Info("again")
+
+	want := []string{`"msg":"one","a":"1"}`, `"msg":"two","b":"2"}`, `"msg":"again","a":"1"}`}
+	logs := capture.Logs()
+	if len(logs) != len(want) {
+		t.Fatalf("输出 %q", logs)
+	}
+	for i, w := range want {
+		if !strings.HasSuffix(logs[i], w) {
+			t.Fatalf("第 %d 条日志 %s，期望以 %s 结尾", i, logs[i], w)
+		}
+	}
+
+	// 字段较少时只分配构建器本身
+	if !raceEnabled {
+		allocs := testing.AllocsPerRun(100, func() {
+			From(ctx).Str("s", "v").Int("i", 1).Debug("debug")
+		})
+		if allocs > 1 {
+			t.Fatalf("级别未开启时分配 %v 次", allocs)
+		}
+	}
+}
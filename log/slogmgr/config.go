@@ -3,48 +3,99 @@ package slogmgr
 import (
 	"io"
 	"log/slog"
-	"os"
-	"path/filepath"
-	"strings"
 
 	"github.com/52debug/go-box/log/logmgr"
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 // getHandlerOption 设置 HandlerOptions
-func getHandlerOption(level slog.Level) *slog.HandlerOptions {
+func getHandlerOption(config logmgr.LogConfig, level slog.Level) *slog.HandlerOptions {
+	timeEnc, err := config.NewTimeEncoder()
+	if err != nil {
+		panic("日志时间配置无效: " + err.Error())
+	}
+	schema := config.GetSchema()
+	keys := config.FieldKeys(logmgr.FieldKeys{
+		Time:       slog.TimeKey,
+		Level:      slog.LevelKey,
+		Message:    slog.MessageKey,
+		Caller:     slog.SourceKey,
+		Stacktrace: logmgr.StacktraceKey,
+	})
+
 	return &slog.HandlerOptions{
-		Level: level,
+		Level:     level,
+		AddSource: !config.DisableCaller,
 		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
-			// 修改时间格式
-			if a.Key == slog.TimeKey {
-				t := a.Value.Time()
-				a.Value = slog.StringValue(t.Format("2006-01-02 15:04:05.000"))
+			// 只处理内置字段
+			if len(groups) > 0 {
+				return a
 			}
-			// 修改级别字段为小写
-			if a.Key == slog.LevelKey {
-				logLevel := a.Value.Any().(slog.Level)
-				a.Value = slog.StringValue(strings.ToLower(logLevel.String()))
+			switch a.Key {
+			case slog.TimeKey:
+				if a.Value.Kind() != slog.KindTime {
+					return a
+				}
+				// 修改时间格式
+				t := a.Value.Time()
+				if timeEnc.Numeric() {
+					a.Value = slog.Int64Value(timeEnc.Int64(t))
+				} else {
+					a.Value = slog.StringValue(timeEnc.Format(t))
+				}
+				a.Key = keys.Time
+			case slog.LevelKey:
+				logLevel, ok := a.Value.Any().(slog.Level)
+				if !ok {
+					return a
+				}
+				// 修改级别字段为小写或 Schema 中的取值
+				a.Value = slog.StringValue(schema.SlogLevelName(logLevel))
+				a.Key = keys.Level
+			case slog.MessageKey:
+				a.Key = keys.Message
+			case slog.SourceKey:
+				// 按配置格式化调用位置
+				if src, ok := a.Value.Any().(*slog.Source); ok {
+					if src.File == "" {
+						// 标准库 log 等没有调用位置的记录
+						return slog.Attr{}
+					}
+					a.Value = slog.StringValue(logmgr.FormatCaller(config.CallerFormat, src.File, src.Line, src.Function))
+				}
+				a.Key = keys.Caller
+			case logmgr.StacktraceKey:
+				a.Key = keys.Stacktrace
 			}
 			return a
 		},
 	}
 }
 
-// newFileWriter 创建文件写入器
+// newEncodingHandler 按编码创建 JSON 或 logfmt 处理器
+func newEncodingHandler(w io.Writer, encoding string, opt *slog.HandlerOptions) slog.Handler {
+	if encoding == logmgr.EncodingLogfmt {
+		return newLogfmtHandler(w, opt)
+	}
+	return slog.NewJSONHandler(w, opt)
+}
+
+// newFileWriter 创建文件写入器，输出到内存或套接字时返回对应的 writer
 func newFileWriter(config logmgr.LogConfig) io.Writer {
-	// 确保日志目录存在
-	dir := filepath.Dir(config.FilePath)
-	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
-		panic("创建日志目录失败: " + err.Error())
+	switch config.GetSink() {
+	case logmgr.SinkMemory:
+		return config.WrapSink(logmgr.SinkMemory, config.CaptureWriter())
+	case logmgr.SinkSocket:
+		w, err := config.NewSocketWriter()
+		if err != nil {
+			panic("创建日志套接字失败: " + err.Error())
+		}
+		return config.WrapSink(logmgr.SinkSocket, w)
 	}
 
-	// 使用 lumberjack 实现日志轮转
-	return &lumberjack.Logger{
-		Filename:   config.FilePath,
-		MaxSize:    config.MaxSize,
-		MaxBackups: config.MaxBackups,
-		MaxAge:     config.MaxAge,
-		Compress:   config.Compress,
+	// 按大小轮转，按配置创建目录和设置权限，配置了密钥时加密
+	w, err := config.NewFileWriter()
+	if err != nil {
+		panic("创建日志文件失败: " + err.Error())
 	}
+	return config.WrapSink(logmgr.SinkFile, w)
 }
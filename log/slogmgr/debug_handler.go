@@ -0,0 +1,33 @@
+package slogmgr
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/52debug/go-box/log/logmgr"
+)
+
+// debugHandler 在 context 通过 logmgr.WithDebug 开启 debug 时放行 debug 级别的记录
+type debugHandler struct {
+	slog.Handler
+}
+
+// withContextDebug 按配置包装单个输出的处理器，未开启 ContextDebug 时原样返回
+func withContextDebug(config logmgr.LogConfig, h slog.Handler) slog.Handler {
+	if !config.ContextDebug {
+		return h
+	}
+	return &debugHandler{Handler: h}
+}
+
+func (h *debugHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.Handler.Enabled(ctx, level) || (level >= slog.LevelDebug && logmgr.DebugEnabled(ctx))
+}
+
+func (h *debugHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &debugHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *debugHandler) WithGroup(name string) slog.Handler {
+	return &debugHandler{Handler: h.Handler.WithGroup(name)}
+}
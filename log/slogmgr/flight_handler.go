@@ -0,0 +1,86 @@
+package slogmgr
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+
+	"github.com/52debug/go-box/log/logmgr"
+)
+
+// flightHandler 将不低于 FlightRecorderLevel 的记录编码到飞行记录器，并按原处理器的级别输出
+type flightHandler struct {
+	slog.Handler              // 原处理器
+	rec          slog.Handler // 编码到飞行记录器的处理器，不限制级别
+	sink         *flightSink
+	level        slog.Level // 飞行记录器保留的最低级别
+}
+
+// flightSink 编码处理器的输出目标，记录当前编码的级别以及是否写入实时输出
+type flightSink struct {
+	mu    sync.Mutex
+	level logmgr.Level
+	live  bool
+	fr    *logmgr.FlightRecorder
+}
+
+func (s *flightSink) Write(p []byte) (int, error) {
+	s.fr.Add(s.level, p, s.live)
+	return len(p), nil
+}
+
+// withFlightRecorder 按配置包装处理器，未开启飞行记录器时原样返回
+func withFlightRecorder(config logmgr.LogConfig, h slog.Handler, fileWriter io.Writer) slog.Handler {
+	fr, err := config.NewFlightRecorder(fileWriter)
+	if err != nil {
+		panic("创建飞行记录器失败: " + err.Error())
+	}
+	logmgr.SetFlightRecorder(fr)
+	if fr == nil {
+		return h
+	}
+	sink := &flightSink{fr: fr}
+	opt := getHandlerOption(config, logmgr.SlogLevelTrace)
+	return &flightHandler{
+		Handler: h,
+		rec:     newEncodingHandler(sink, config.FileEncoding, opt),
+		sink:    sink,
+		level:   config.GetFlightRecorderLevel().SlogLevel(),
+	}
+}
+
+func (h *flightHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level || h.Handler.Enabled(ctx, level)
+}
+
+func (h *flightHandler) Handle(ctx context.Context, r slog.Record) error {
+	live := h.Handler.Enabled(ctx, r.Level)
+	// 先保存到飞行记录器: error 记录触发的转储写在该记录之前
+	if r.Level >= h.level {
+		// 编码处理器在 Handle 中同步写入 sink，加锁保证写入时的级别与记录一致
+		h.sink.mu.Lock()
+		h.sink.level = logmgr.LevelFromSlog(r.Level)
+		h.sink.live = live
+		_ = h.rec.Handle(ctx, r)
+		h.sink.mu.Unlock()
+	}
+	if live {
+		return h.Handler.Handle(ctx, r)
+	}
+	return nil
+}
+
+func (h *flightHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.Handler = h.Handler.WithAttrs(attrs)
+	h2.rec = h.rec.WithAttrs(attrs)
+	return &h2
+}
+
+func (h *flightHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.Handler = h.Handler.WithGroup(name)
+	h2.rec = h.rec.WithGroup(name)
+	return &h2
+}
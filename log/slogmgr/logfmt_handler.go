@@ -0,0 +1,172 @@
+package slogmgr
+
+import (
+	"context"
+	"encoding"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/52debug/go-box/log/logmgr"
+)
+
+// logfmtHandler 输出 logfmt 格式，分组展开为以点分隔的键
+type logfmtHandler struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	opts   slog.HandlerOptions
+	groups []string // 当前分组，用于 ReplaceAttr
+	prefix string   // 当前分组前缀 a.b.
+	attrs  []byte   // WithAttrs 预先渲染的属性
+}
+
+func newLogfmtHandler(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	h := &logfmtHandler{w: w, mu: &sync.Mutex{}}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
+}
+
+func (h *logfmtHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+func (h *logfmtHandler) Handle(ctx context.Context, r slog.Record) error {
+	var buf []byte
+
+	// 内置字段
+	if !r.Time.IsZero() {
+		buf = h.appendBuiltin(buf, slog.Time(slog.TimeKey, r.Time))
+	}
+	buf = h.appendBuiltin(buf, slog.Any(slog.LevelKey, r.Level))
+	if h.opts.AddSource && r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		buf = h.appendBuiltin(buf, slog.Any(slog.SourceKey, &slog.Source{
+			Function: frame.Function,
+			File:     frame.File,
+			Line:     frame.Line,
+		}))
+	}
+	buf = h.appendBuiltin(buf, slog.String(slog.MessageKey, r.Message))
+
+	// 预渲染属性与记录属性
+	if len(h.attrs) > 0 {
+		buf = append(buf, ' ')
+		buf = append(buf, h.attrs...)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		buf = h.appendAttr(buf, h.groups, h.prefix, a)
+		return true
+	})
+	buf = append(buf, '\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(buf)
+	return err
+}
+
+func (h *logfmtHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.attrs = append([]byte(nil), h.attrs...)
+	for _, a := range attrs {
+		h2.attrs = h.appendAttr(h2.attrs, h.groups, h.prefix, a)
+	}
+	return &h2
+}
+
+func (h *logfmtHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(append([]string(nil), h.groups...), name)
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+// appendBuiltin 追加内置字段，内置字段不属于任何分组
+func (h *logfmtHandler) appendBuiltin(buf []byte, a slog.Attr) []byte {
+	if h.opts.ReplaceAttr != nil {
+		a = h.opts.ReplaceAttr(nil, a)
+		if a.Equal(slog.Attr{}) {
+			return buf
+		}
+	}
+	return logmgr.AppendLogfmt(buf, a.Key, formatValue(a.Value.Resolve()))
+}
+
+// appendAttr 追加属性，分组属性展开为 prefix.key
+func (h *logfmtHandler) appendAttr(buf []byte, groups []string, prefix string, a slog.Attr) []byte {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return buf
+		}
+		if a.Key != "" {
+			groups = append(groups[:len(groups):len(groups)], a.Key)
+			prefix += a.Key + "."
+		}
+		for _, ga := range attrs {
+			buf = h.appendAttr(buf, groups, prefix, ga)
+		}
+		return buf
+	}
+	if h.opts.ReplaceAttr != nil {
+		a = h.opts.ReplaceAttr(groups, a)
+		a.Value = a.Value.Resolve()
+	}
+	if a.Equal(slog.Attr{}) {
+		return buf
+	}
+	return logmgr.AppendLogfmt(buf, prefix+a.Key, formatValue(a.Value))
+}
+
+// formatValue 将属性值转换为文本
+func formatValue(v slog.Value) string {
+	switch v.Kind() {
+	case slog.KindString:
+		return v.String()
+	case slog.KindInt64:
+		return strconv.FormatInt(v.Int64(), 10)
+	case slog.KindUint64:
+		return strconv.FormatUint(v.Uint64(), 10)
+	case slog.KindFloat64:
+		return strconv.FormatFloat(v.Float64(), 'g', -1, 64)
+	case slog.KindBool:
+		return strconv.FormatBool(v.Bool())
+	case slog.KindDuration:
+		return v.Duration().String()
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339Nano)
+	case slog.KindAny:
+		switch x := v.Any().(type) {
+		case *slog.Source:
+			return x.File + ":" + strconv.Itoa(x.Line)
+		case encoding.TextMarshaler:
+			if data, err := x.MarshalText(); err == nil {
+				return string(data)
+			}
+		case error:
+			return x.Error()
+		case []byte:
+			return string(x)
+		}
+		return fmt.Sprint(v.Any())
+	default:
+		return v.String()
+	}
+}
@@ -0,0 +1,62 @@
+package slogmgr
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+func TestLogfmtHandler(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(l *slog.Logger)
+		want string
+	}{
+		{
+			name: "escape",
+			log:  func(l *slog.Logger) { l.Info("hello world", "path", `C:\tmp`, "q", `a "b"`, "empty", "") },
+			want: `level=INFO msg="hello world" path="C:\\tmp" q="a \"b\"" empty=""` + "\n",
+		},
+		{
+			name: "multiline",
+			log:  func(l *slog.Logger) { l.Warn("x", "err", errors.New("line1\nline2")) },
+			want: `level=WARN msg=x err="line1\nline2"` + "\n",
+		},
+		{
+			name: "groups",
+			log: func(l *slog.Logger) {
+				l.With("a", 1).WithGroup("req").With("id", "r1").Info("m", slog.Group("user", "name", "bob"), "n", 2)
+			},
+			want: `level=INFO msg=m a=1 req.id=r1 req.user.name=bob req.n=2` + "\n",
+		},
+		{
+			name: "empty group",
+			log:  func(l *slog.Logger) { l.Info("m", slog.Group("g"), "k", "v") },
+			want: `level=INFO msg=m k=v` + "\n",
+		},
+		{
+			name: "key escape",
+			log:  func(l *slog.Logger) { l.Info("m", "a b", 1, "x=y", true) },
+			want: `level=INFO msg=m a_b=1 x_y=true` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			// 去掉时间以便比较输出
+			h := newLogfmtHandler(&buf, &slog.HandlerOptions{
+				ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+					if len(groups) == 0 && a.Key == slog.TimeKey {
+						return slog.Attr{}
+					}
+					return a
+				},
+			})
+			tt.log(slog.New(h))
+			if got := buf.String(); got != tt.want {
+				t.Fatalf("得到 %s期望 %s", got, tt.want)
+			}
+		})
+	}
+}
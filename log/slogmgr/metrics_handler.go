@@ -0,0 +1,58 @@
+package slogmgr
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/52debug/go-box/log/logmgr"
+)
+
+// metricsHandler 按级别和模块统计输出的记录，模块取顶层的 module 属性
+type metricsHandler struct {
+	slog.Handler
+	module  string
+	grouped bool // 已打开分组，之后的属性不再是顶层属性
+}
+
+// withMetrics 按配置包装处理器，未开启统计时原样返回
+func withMetrics(config logmgr.LogConfig, h slog.Handler) slog.Handler {
+	if !config.Metrics {
+		return h
+	}
+	logmgr.PublishMetrics()
+	return &metricsHandler{Handler: h}
+}
+
+func (h *metricsHandler) Handle(ctx context.Context, r slog.Record) error {
+	module := h.module
+	if !h.grouped {
+		r.Attrs(func(a slog.Attr) bool {
+			if a.Key == logmgr.ModuleKey {
+				module = a.Value.String()
+				return false
+			}
+			return true
+		})
+	}
+	logmgr.CountRecord(logmgr.LevelFromSlog(r.Level), module)
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *metricsHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := &metricsHandler{Handler: h.Handler.WithAttrs(attrs), module: h.module, grouped: h.grouped}
+	if !h.grouped {
+		for _, a := range attrs {
+			if a.Key == logmgr.ModuleKey {
+				h2.module = a.Value.String()
+			}
+		}
+	}
+	return h2
+}
+
+func (h *metricsHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &metricsHandler{Handler: h.Handler.WithGroup(name), module: h.module, grouped: true}
+}
@@ -0,0 +1,5 @@
+//go:build !race
+
+package slogmgr
+
+const raceEnabled = false
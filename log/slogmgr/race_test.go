@@ -0,0 +1,6 @@
+//go:build race
+
+package slogmgr
+
+// race 模式下 sync.Pool 会随机丢弃对象，分配次数不稳定
+const raceEnabled = true
@@ -0,0 +1,38 @@
+package slogmgr
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/52debug/go-box/log/logmgr"
+)
+
+// samplingHandler 按级别和消息采样，丢弃的记录不再交给后续处理器
+type samplingHandler struct {
+	slog.Handler
+	sampler *logmgr.Sampler
+}
+
+// withSampling 按配置包装处理器，未开启采样时原样返回
+func withSampling(config logmgr.LogConfig, h slog.Handler) slog.Handler {
+	sampler := config.NewSampler()
+	if sampler == nil {
+		return h
+	}
+	return &samplingHandler{Handler: h, sampler: sampler}
+}
+
+func (h *samplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.sampler.Allow(logmgr.LevelFromSlog(r.Level), r.Message) {
+		return nil
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingHandler{Handler: h.Handler.WithAttrs(attrs), sampler: h.sampler}
+}
+
+func (h *samplingHandler) WithGroup(name string) slog.Handler {
+	return &samplingHandler{Handler: h.Handler.WithGroup(name), sampler: h.sampler}
+}
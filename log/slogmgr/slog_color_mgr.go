@@ -2,52 +2,41 @@ package slogmgr
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"log/slog"
-	"path/filepath"
+	"runtime"
 	"strconv"
-	"strings"
+	"sync"
+	"time"
 
+	"github.com/52debug/go-box/log/logbridge"
 	"github.com/52debug/go-box/log/logmgr"
 )
 
 func SetupWithColor(config logmgr.LogConfig) {
-	var level slog.Level
-	switch config.Level {
-	case "debug":
-		level = slog.LevelDebug
-	case "info":
-		level = slog.LevelInfo
-	case "warn":
-		level = slog.LevelWarn
-	case "error":
-		level = slog.LevelError
-	default:
-		level = slog.LevelInfo
-	}
+	config = config.Checked()
+	level := config.GetLevel().SlogLevel()
 
-	handlerOpt := getHandlerOption(level)
+	handlerOpt := getHandlerOption(config, level)
 
 	var handler slog.Handler
+	var fileWriter io.Writer
 
 	switch config.Output {
-	case "console":
-		// 控制台输出使用带颜色的文本格式
-		handler = newColorHandler(nil)
-	case "file":
-		// 文件输出使用 JSON 格式
-		writer := newFileWriter(config)
-		handler = slog.NewJSONHandler(writer, handlerOpt)
-	case "both":
-		// 控制台使用带颜色的文本格式，文件使用 JSON 格式
-		fileWriter := newFileWriter(config)
+	case "file", "memory", "socket":
+		// 文件输出默认使用 JSON 格式
+		fileWriter = newFileWriter(config)
+		handler = withContextDebug(config, newEncodingHandler(fileWriter, config.FileEncoding, handlerOpt))
+	case "both", logmgr.OutputConsoleSocket:
+		// 控制台默认使用带颜色的文本格式，文件默认使用 JSON 格式
+		fileWriter = newFileWriter(config)
 
 		// 控制台处理器（带颜色）
-		var consoleHandler slog.Handler
-		consoleHandler = newColorHandler(nil)
+		consoleHandler := withContextDebug(config, newConsoleHandler(config, logmgr.EncodingText, handlerOpt))
 
-		// 文件处理器（JSON 格式）
-		var fileHandler slog.Handler
-		fileHandler = slog.NewJSONHandler(fileWriter, handlerOpt)
+		// 文件处理器
+		fileHandler := withContextDebug(config, newEncodingHandler(fileWriter, config.FileEncoding, handlerOpt))
 
 		// 合并处理器
 		handler = &multiHandler{
@@ -55,88 +44,228 @@ func SetupWithColor(config logmgr.LogConfig) {
 		}
 	default:
 		// 默认使用带颜色的文本格式
-		handler = newColorHandler(nil)
+		handler = withContextDebug(config, newConsoleHandler(config, logmgr.EncodingText, handlerOpt))
 	}
 
-	// 设置默认日志记录器
-	slog.SetDefault(slog.New(handler))
+	// 设置默认日志记录器，并将标准库 log、zap、zerolog 路由到该处理器；
+	// 飞行记录器位于采样之前，保留被采样丢弃的记录
+	handler = withFlightRecorder(config, withSampling(config, withMetrics(config, handler)), fileWriter)
+	logbridge.InstallSlog(withStacktrace(config, handler))
+	logmgr.SetSync(nil) // 处理器直接写入，无需刷新
 }
 
-// colorHandler 直接输出带颜色的文本日志
+// colorHandler 直接输出带颜色的文本日志，使用池化的缓冲区，常见类型的字段值直接追加而不转换为字符串
+type colorHandler struct {
+	formatter *logmgr.Formatter
+	out       io.Writer
+	level     slog.Leveler
+	caller    bool   // 是否输出调用位置
+	prefix    []byte // 当前分组前缀 a.b.
+	attrs     []byte // WithAttrs 预先渲染的字段
+}
 
-type colorHandler struct{}
+func newColorHandler(out io.Writer, formatter *logmgr.Formatter, level slog.Leveler, caller bool) slog.Handler {
+	return &colorHandler{
+		formatter: formatter,
+		out:       out,
+		level:     level,
+		caller:    caller,
+	}
+}
 
-func newColorHandler(_ slog.Handler) slog.Handler {
-	return &colorHandler{}
+// colorBuffer 渲染一条日志使用的缓冲区
+type colorBuffer struct {
+	line   []byte
+	fields []byte
+	prefix []byte // 记录中分组属性的前缀
 }
 
+var colorBufferPool = sync.Pool{
+	New: func() interface{} {
+		return &colorBuffer{line: make([]byte, 0, 256), fields: make([]byte, 0, 256), prefix: make([]byte, 0, 64)}
+	},
+}
+
+// 超过该大小的缓冲区不放回池中，避免个别大日志长期占用内存
+const maxPooledBuffer = 64 << 10
+
 func (ch *colorHandler) Enabled(ctx context.Context, level slog.Level) bool {
-	// 始终启用所有级别
-	return true
+	return level >= ch.level.Level()
 }
 
 func (ch *colorHandler) Handle(ctx context.Context, r slog.Record) error {
-	// 对于控制台输出，我们将使用自定义的彩色文本格式
-	// 获取颜色
-	var levelColor string
-	switch r.Level {
-	case slog.LevelDebug:
-		levelColor = logmgr.ColorDebug
-	case slog.LevelInfo:
-		levelColor = logmgr.ColorInfo
-	case slog.LevelWarn:
-		levelColor = logmgr.ColorWarn
-	case slog.LevelError:
-		levelColor = logmgr.ColorError
-	default:
-		levelColor = logmgr.ColorReset
-	}
-
-	// 格式化时间
-	timeStr := r.Time.Format("2006-01-02 15:04:05.000")
-
-	// 格式化源信息
-	var sourceStr string
-	if r.PC != 0 {
-		src := r.Source()
-		if src != nil {
-			// 只保留文件名和函数名
-			filename := filepath.Base(src.File)
-			funcName := src.Function
-			if idx := strings.LastIndex(funcName, "."); idx != -1 {
-				funcName = funcName[idx+1:]
-			}
-			sourceStr = logmgr.ColorSource + " " + filename + ":" + funcName + ":" + strconv.Itoa(src.Line) + logmgr.ColorReset
+	buf := colorBufferPool.Get().(*colorBuffer)
+	defer func() {
+		if cap(buf.line) <= maxPooledBuffer && cap(buf.fields) <= maxPooledBuffer {
+			colorBufferPool.Put(buf)
 		}
+	}()
+
+	rec := logmgr.Record{
+		Time:    r.Time,
+		Level:   logmgr.LevelFromSlog(r.Level),
+		Message: r.Message,
 	}
 
-	// 构建彩色日志行
-	levelStr := strings.ToUpper(r.Level.String())
-	logLine := logmgr.ColorTime + "[" + timeStr + "]" + logmgr.ColorReset + " " +
-		levelColor + "[" + levelStr + "]" + logmgr.ColorReset +
-		sourceStr + " " +
-		levelColor + r.Message + logmgr.ColorReset
+	// 源信息
+	if ch.caller && r.PC != 0 {
+		frame := callerFrame(r.PC)
+		rec.File = frame.File
+		rec.Line = frame.Line
+		rec.Function = frame.Function
+	}
 
-	// 添加属性
+	// 添加属性，堆栈单独输出在日志行之后
+	var stack string
+	fields := append(buf.fields[:0], ch.attrs...)
+	buf.prefix = append(buf.prefix[:0], ch.prefix...)
 	r.Attrs(func(a slog.Attr) bool {
-		logLine += " " + logmgr.ColorMessage + a.Key + "=" + logmgr.ColorReset + a.Value.String()
+		if len(ch.prefix) == 0 && a.Key == logmgr.StacktraceKey {
+			stack = a.Value.String()
+			return true
+		}
+		// 分组展开时在 buf.prefix 之后追加，各字段从 ch.prefix 重新开始
+		fields = ch.appendAttr(fields, buf, buf.prefix[:len(ch.prefix)], a)
 		return true
 	})
+	buf.fields = fields
+	rec.Fields = fields
+
+	line := ch.formatter.Append(buf.line[:0], &rec)
+	if stack != "" {
+		line = append(append(line, stack...), '\n')
+	}
+	buf.line = line
 
 	// 输出到控制台
-	println(logLine)
+	_, err := ch.out.Write(line)
+	return err
+}
+
+// appendAttr 追加一个字段，分组展开为以点分隔的键
+func (ch *colorHandler) appendAttr(fields []byte, buf *colorBuffer, prefix []byte, a slog.Attr) []byte {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix = append(append(prefix, a.Key...), '.')
+		}
+		for _, ga := range a.Value.Group() {
+			fields = ch.appendAttr(fields, buf, prefix, ga)
+		}
+		// 保留扩展后的容量供之后的记录使用
+		if cap(prefix) > cap(buf.prefix) {
+			buf.prefix = prefix[:0]
+		}
+		return fields
+	}
+	fields = ch.formatter.BeginField(fields, prefix, a.Key)
+	fields = appendValue(fields, a.Value)
+	return ch.formatter.EndField(fields)
+}
+
+// appendValue 按类型追加字段值
+func appendValue(buf []byte, v slog.Value) []byte {
+	switch v.Kind() {
+	case slog.KindString:
+		return append(buf, v.String()...)
+	case slog.KindInt64:
+		return strconv.AppendInt(buf, v.Int64(), 10)
+	case slog.KindUint64:
+		return strconv.AppendUint(buf, v.Uint64(), 10)
+	case slog.KindFloat64:
+		return strconv.AppendFloat(buf, v.Float64(), 'g', -1, 64)
+	case slog.KindBool:
+		return strconv.AppendBool(buf, v.Bool())
+	case slog.KindDuration:
+		return appendDuration(buf, v.Duration())
+	case slog.KindTime:
+		return v.Time().AppendFormat(buf, time.RFC3339Nano)
+	}
+	switch x := v.Any().(type) {
+	case error:
+		return append(buf, x.Error()...)
+	case []byte:
+		return append(buf, x...)
+	default:
+		return fmt.Append(buf, x)
+	}
+}
+
+// appendDuration 按 time.Duration.String 的格式追加时长，如 1.5ms、1h2m3.5s
+func appendDuration(buf []byte, d time.Duration) []byte {
+	if d == 0 {
+		return append(buf, "0s"...)
+	}
+	u := uint64(d)
+	if d < 0 {
+		buf = append(buf, '-')
+		u = -u
+	}
+	switch {
+	case u < uint64(time.Microsecond):
+		return append(strconv.AppendUint(buf, u, 10), "ns"...)
+	case u < uint64(time.Millisecond):
+		return append(strconv.AppendFloat(buf, float64(u)/1e3, 'f', -1, 64), "µs"...)
+	case u < uint64(time.Second):
+		return append(strconv.AppendFloat(buf, float64(u)/1e6, 'f', -1, 64), "ms"...)
+	}
+	if h := u / uint64(time.Hour); h > 0 {
+		buf = append(strconv.AppendUint(buf, h, 10), 'h')
+		buf = append(strconv.AppendUint(buf, u/uint64(time.Minute)%60, 10), 'm')
+	} else if m := u / uint64(time.Minute); m > 0 {
+		buf = append(strconv.AppendUint(buf, m, 10), 'm')
+	}
+	return append(strconv.AppendFloat(buf, float64(u%uint64(time.Minute))/1e9, 'f', -1, 64), 's')
+}
+
+// 调用位置缓存，PC 对应的栈帧不会变化；超过 maxCallerCache 个调用点后不再缓存新的调用点
+const maxCallerCache = 4096
+
+var (
+	callerMu    sync.RWMutex
+	callerCache = map[uintptr]runtime.Frame{}
+)
 
-	return nil
+// callerFrame 返回 pc 对应的栈帧
+func callerFrame(pc uintptr) runtime.Frame {
+	callerMu.RLock()
+	frame, ok := callerCache[pc]
+	callerMu.RUnlock()
+	if ok {
+		return frame
+	}
+	frame, _ = runtime.CallersFrames([]uintptr{pc}).Next()
+	callerMu.Lock()
+	if len(callerCache) < maxCallerCache {
+		callerCache[pc] = frame
+	}
+	callerMu.Unlock()
+	return frame
 }
 
 func (ch *colorHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	// 简单返回自身，因为我们不处理属性的持久化
-	return ch
+	if len(attrs) == 0 {
+		return ch
+	}
+	h := *ch
+	h.attrs = append([]byte(nil), ch.attrs...)
+	buf := &colorBuffer{}
+	for _, a := range attrs {
+		h.attrs = ch.appendAttr(h.attrs, buf, append([]byte(nil), ch.prefix...), a)
+	}
+	return &h
 }
 
 func (ch *colorHandler) WithGroup(name string) slog.Handler {
-	// 简单返回自身，因为我们不处理分组
-	return ch
+	if name == "" {
+		return ch
+	}
+	h := *ch
+	h.prefix = append(append(append([]byte(nil), ch.prefix...), name...), '.')
+	return &h
 }
 
 // multiHandler 允许多个处理器处理同一个日志记录
@@ -0,0 +1,72 @@
+package slogmgr
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/52debug/go-box/log/logmgr"
+)
+
+func TestColorHandlerLevel(t *testing.T) {
+	tests := []struct {
+		level        string
+		contextDebug bool
+		want         []string // 期望输出的消息，ctx-debug 为开启 WithDebug 的 debug 记录
+	}{
+		{level: "trace", want: []string{"trace", "debug", "info", "warn", "ctx-debug"}},
+		{level: "debug", want: []string{"debug", "info", "warn", "ctx-debug"}},
+		{level: "info", want: []string{"info", "warn"}},
+		{level: "warn", want: []string{"warn"}},
+		{level: "warn", contextDebug: true, want: []string{"warn", "ctx-debug"}},
+	}
+	for _, tt := range tests {
+		name := tt.level
+		if tt.contextDebug {
+			name += "+context"
+		}
+		t.Run(name, func(t *testing.T) {
+			config := logmgr.LogConfig{Level: tt.level, Theme: "monochrome", Template: "{msg}", ContextDebug: tt.contextDebug}
+			formatter, err := config.NewFormatter()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			h := withContextDebug(config, newColorHandler(&buf, formatter, config.GetLevel().SlogLevel(), false))
+			logger := slog.New(h).With("k", 1).WithGroup("g")
+
+			ctx := context.Background()
+			logger.Log(ctx, logmgr.SlogLevelTrace, "trace")
+			logger.DebugContext(ctx, "debug")
+			logger.InfoContext(ctx, "info")
+			logger.WarnContext(ctx, "warn")
+			logger.DebugContext(logmgr.WithDebug(ctx), "ctx-debug")
+
+			got := strings.Fields(buf.String())
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("输出 %q，期望 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColorHandlerGroups(t *testing.T) {
+	formatter, err := logmgr.LogConfig{Theme: "monochrome", Template: "{msg} {fields}"}.NewFormatter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	logger := slog.New(newColorHandler(&buf, formatter, slog.LevelInfo, false)).With("a", 1).WithGroup("req")
+	// 相邻的记录复用缓冲区，分组前缀不能残留
+	logger.Info("m1", slog.Group("user", "name", "bob", slog.Group("role", "id", 7)), "n", 2)
+	logger.Info("m2", "n", 3, slog.Group("x", "y", true))
+
+	want := "m1 a=1 req.user.name=bob req.user.role.id=7 req.n=2\nm2 a=1 req.n=3 req.x.y=true\n"
+	// 只比较文本，去掉颜色
+	if got := regexp.MustCompile("\x1b\\[[0-9;]*m").ReplaceAllString(buf.String(), ""); got != want {
+		t.Fatalf("输出 %q，期望 %q", got, want)
+	}
+}
@@ -5,41 +5,61 @@ import (
 	"log/slog"
 	"os"
 
+	"github.com/52debug/go-box/log/logbridge"
 	"github.com/52debug/go-box/log/logmgr"
+	"github.com/mattn/go-colorable"
 )
 
 func Setup(config logmgr.LogConfig) {
-	var level slog.Level
-	switch config.Level {
-	case "debug":
-		level = slog.LevelDebug
-	case "info":
-		level = slog.LevelInfo
-	case "warn":
-		level = slog.LevelWarn
-	case "error":
-		level = slog.LevelError
-	default:
-		level = slog.LevelInfo
-	}
+	config = config.Checked()
+	level := config.GetLevel().SlogLevel()
+
+	handlerOpt := getHandlerOption(config, level)
 
-	var writer io.Writer
+	var handlers []slog.Handler
+	var fileWriter io.Writer
 	switch config.Output {
-	case "console":
-		writer = os.Stdout
-	case "file":
-		writer = newFileWriter(config)
-	case "both":
-		fileWriter := newFileWriter(config)
-		writer = io.MultiWriter(os.Stdout, fileWriter)
+	case "file", "memory", "socket":
+		fileWriter = newFileWriter(config)
+		handlers = append(handlers, newEncodingHandler(fileWriter, config.FileEncoding, handlerOpt))
+	case "both", logmgr.OutputConsoleSocket:
+		fileWriter = newFileWriter(config)
+		handlers = append(handlers,
+			newConsoleHandler(config, logmgr.EncodingJSON, handlerOpt),
+			newEncodingHandler(fileWriter, config.FileEncoding, handlerOpt))
 	default:
-		writer = os.Stdout
+		handlers = append(handlers, newConsoleHandler(config, logmgr.EncodingJSON, handlerOpt))
 	}
 
-	handlerOpt := getHandlerOption(level)
+	for i, h := range handlers {
+		handlers[i] = withContextDebug(config, h)
+	}
 
-	// 创建 JSON 格式处理器
-	handler := slog.NewJSONHandler(writer, handlerOpt)
-	// 设置默认日志记录器
-	slog.SetDefault(slog.New(handler))
+	var handler slog.Handler
+	if len(handlers) == 1 {
+		handler = handlers[0]
+	} else {
+		handler = &multiHandler{handlers: handlers}
+	}
+	// 设置默认日志记录器，并将标准库 log、zap、zerolog 路由到该处理器；
+	// 飞行记录器位于采样之前，保留被采样丢弃的记录
+	handler = withFlightRecorder(config, withSampling(config, withMetrics(config, handler)), fileWriter)
+	logbridge.InstallSlog(withStacktrace(config, handler))
+	logmgr.SetSync(nil) // 处理器直接写入，无需刷新
+}
+
+// newConsoleHandler 创建控制台处理器，未配置编码时使用 def
+func newConsoleHandler(config logmgr.LogConfig, def string, opt *slog.HandlerOptions) slog.Handler {
+	encoding := config.ConsoleEncoding
+	if encoding == "" {
+		encoding = def
+	}
+	if encoding == logmgr.EncodingText {
+		formatter, err := config.NewFormatter()
+		if err != nil {
+			panic("创建控制台格式失败: " + err.Error())
+		}
+		return newColorHandler(config.WrapSink(logmgr.SinkConsole, colorable.NewColorableStdout()), formatter, opt.Level, !config.DisableCaller)
+	}
+	return newEncodingHandler(config.WrapSink(logmgr.SinkConsole, os.Stdout), encoding, opt)
 }
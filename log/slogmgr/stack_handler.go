@@ -0,0 +1,52 @@
+package slogmgr
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/52debug/go-box/log/logmgr"
+)
+
+// 记录堆栈时跳过的日志库内部栈帧
+var stackSkipPrefixes = []string{
+	"github.com/52debug/go-box/log/slogmgr.",
+	"github.com/52debug/go-box/log/logbridge.",
+	"log/slog.",
+	"log.",
+	"go.uber.org/zap",
+	"github.com/rs/zerolog",
+}
+
+// stackHandler 为达到指定级别的记录附加调用栈，context 中携带调用栈时优先使用
+type stackHandler struct {
+	slog.Handler
+	level   slog.Level
+	enabled bool
+}
+
+// withStacktrace 按配置包装处理器
+func withStacktrace(config logmgr.LogConfig, h slog.Handler) slog.Handler {
+	level, ok := config.GetStacktraceLevel()
+	return &stackHandler{Handler: h, level: level.SlogLevel(), enabled: ok}
+}
+
+func (h *stackHandler) Handle(ctx context.Context, r slog.Record) error {
+	if stack, ok := logmgr.StacktraceFromContext(ctx); ok {
+		if stack != "" {
+			r = r.Clone()
+			r.AddAttrs(slog.String(logmgr.StacktraceKey, stack))
+		}
+	} else if h.enabled && r.Level >= h.level {
+		r = r.Clone()
+		r.AddAttrs(slog.String(logmgr.StacktraceKey, logmgr.Stacktrace(0, stackSkipPrefixes...)))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *stackHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &stackHandler{Handler: h.Handler.WithAttrs(attrs), level: h.level, enabled: h.enabled}
+}
+
+func (h *stackHandler) WithGroup(name string) slog.Handler {
+	return &stackHandler{Handler: h.Handler.WithGroup(name), level: h.level, enabled: h.enabled}
+}
@@ -0,0 +1,50 @@
+package zaplogmgr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/52debug/go-box/log/logmgr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const benchMessage = "请求完成"
+
+// BenchmarkSetup 在控制台(写入空设备)和文件输出下记录日志，disabled 记录低于日志级别的日志
+func BenchmarkSetup(b *testing.B) {
+	for _, output := range []string{"console", "file", "disabled"} {
+		b.Run(output, func(b *testing.B) {
+			null, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+			if err != nil {
+				b.Fatal(err)
+			}
+			stdout := os.Stdout
+			os.Stdout = null
+			defer func() {
+				os.Stdout = stdout
+				null.Close()
+			}()
+
+			config := logmgr.LogConfig{Level: "info", Output: output, FilePath: filepath.Join(b.TempDir(), "bench.log")}
+			level := zapcore.InfoLevel
+			if output == "disabled" {
+				config.Output = "file"
+				level = zapcore.DebugLevel
+			}
+			Setup(config)
+			logger := zap.L()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				logger.Log(level, benchMessage, zap.String("method", "GET"), zap.Int("status", 200),
+					zap.Duration("latency", 1500*time.Microsecond), zap.Int64("request_id", int64(i)))
+			}
+			b.StopTimer()
+			logmgr.Sync()
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// 使用外部测试包，包内函数的栈帧会被当作日志库内部栈帧跳过
+package zaplogmgr_test
+
+import (
+	"log"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/52debug/go-box/log/logmgr"
+	"github.com/52debug/go-box/log/zaplogmgr"
+	zlog "github.com/rs/zerolog/log"
+	"go.uber.org/zap"
+)
+
+// logInfoError 分别记录一条 info 和 error 日志，调用位置和堆栈指向这里
+func logInfoError() {
+	zap.L().Info("info")
+	zap.L().Error("error")
+}
+
+func TestCallerAndStacktrace(t *testing.T) {
+	tests := []struct {
+		name   string
+		config logmgr.LogConfig
+		caller string // 调用位置的正则，为空表示不记录
+		stack  []bool // info 和 error 是否记录堆栈
+	}{
+		{name: "default", caller: `^zaplogmgr/caller_test\.go:\d+$`, stack: []bool{false, true}},
+		{name: "full", config: logmgr.LogConfig{CallerFormat: "full"}, caller: `^/.+/log/zaplogmgr/caller_test\.go:\d+$`, stack: []bool{false, true}},
+		{name: "function", config: logmgr.LogConfig{CallerFormat: "function"}, caller: `^zaplogmgr_test\.logInfoError:\d+$`, stack: []bool{false, true}},
+		{name: "disabled", config: logmgr.LogConfig{DisableCaller: true}, stack: []bool{false, true}},
+		{name: "stack info", config: logmgr.LogConfig{StacktraceLevel: "info"}, caller: `^zaplogmgr/`, stack: []bool{true, true}},
+		{name: "stack none", config: logmgr.LogConfig{StacktraceLevel: "none"}, caller: `^zaplogmgr/`, stack: []bool{false, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, capture := logmgr.Test()
+			c = c.Override(tt.config, "DisableCaller", "CallerFormat", "StacktraceLevel")
+			zaplogmgr.Setup(c)
+			logInfoError()
+
+			entries := capture.Entries()
+			if len(entries) != 2 {
+				t.Fatalf("捕获 %d 条日志", len(entries))
+			}
+			for i, e := range entries {
+				if tt.caller == "" && e.Caller != "" || tt.caller != "" && !regexp.MustCompile(tt.caller).MatchString(e.Caller) {
+					t.Errorf("%s 的调用位置 %q，期望匹配 %q", e.Message, e.Caller, tt.caller)
+				}
+				if hasStack := strings.Contains(e.Stack, "zaplogmgr_test.logInfoError"); hasStack != tt.stack[i] {
+					t.Errorf("%s 的堆栈 %q，期望记录: %v", e.Message, e.Stack, tt.stack[i])
+				}
+			}
+		})
+	}
+}
+
+// logSources 通过各日志库分别记录一条 info 日志
+func logSources() {
+	slog.Info("slog")
+	zap.L().Info("zap")
+	zlog.Info().Msg("zerolog")
+	log.Print("log")
+}
+
+// 路由到当前后端的其他日志库同样按 StacktraceLevel 记录堆栈
+func TestBridgeStacktrace(t *testing.T) {
+	c, capture := logmgr.Test()
+	c.StacktraceLevel = "info"
+	zaplogmgr.Setup(c)
+	logSources()
+
+	var got []string
+	for _, e := range capture.Entries() {
+		got = append(got, e.Message)
+		if !strings.Contains(e.Stack, "zaplogmgr_test.logSources") {
+			t.Errorf("%s 的堆栈 %q", e.Message, e.Stack)
+		}
+	}
+	if strings.Join(got, ",") != "slog,zap,zerolog,log" {
+		t.Fatalf("输出 %q", got)
+	}
+}
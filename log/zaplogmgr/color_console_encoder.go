@@ -10,80 +10,86 @@ import (
 var bufPool = buffer.NewPool()
 
 type coloredConsoleEncoder struct {
-	zapcore.Encoder
+	*fieldEncoder
+	formatter *logmgr.Formatter
 }
 
-func newColoredConsoleEncoder() zapcore.Encoder {
+func newColoredConsoleEncoder(formatter *logmgr.Formatter, timeEnc logmgr.TimeEncoder) zapcore.Encoder {
 	return &coloredConsoleEncoder{
-		Encoder: zapcore.NewConsoleEncoder(zapcore.EncoderConfig{
-			TimeKey:        "",
-			LevelKey:       "",
-			NameKey:        "",
-			CallerKey:      "",
-			FunctionKey:    "",
-			MessageKey:     "",
-			StacktraceKey:  "",
-			LineEnding:     zapcore.DefaultLineEnding,
-			EncodeLevel:    zapcore.CapitalLevelEncoder,
-			EncodeTime:     zapcore.ISO8601TimeEncoder,
-			EncodeDuration: zapcore.SecondsDurationEncoder,
-			EncodeCaller:   zapcore.ShortCallerEncoder,
-		}),
+		fieldEncoder: newFieldEncoder(timeEnc, formatter.AppendField),
+		formatter:    formatter,
 	}
 }
 
 func (e *coloredConsoleEncoder) Clone() zapcore.Encoder {
 	return &coloredConsoleEncoder{
-		Encoder: e.Encoder.Clone(),
+		fieldEncoder: e.fieldEncoder.clone(),
+		formatter:    e.formatter,
 	}
 }
 
 func (e *coloredConsoleEncoder) EncodeEntry(entry zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
-	buf := bufPool.Get()
-
-	buf.AppendString(logmgr.ColorDebug)
-	buf.AppendString(entry.Time.Format("2006-01-02 15:04:05.000"))
-	buf.AppendString(logmgr.ColorReset)
-	buf.AppendString(" [")
-
-	buf.AppendString(getLevelColor(entry.Level))
-	buf.AppendString(entry.Level.CapitalString())
-	buf.AppendString(logmgr.ColorReset)
-
-	buf.AppendString("] ")
+	enc := e.fieldEncoder.clone()
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
 
+	rec := logmgr.Record{
+		Time:    entry.Time,
+		Level:   toLevel(entry.Level),
+		Message: entry.Message,
+		Fields:  enc.buf,
+	}
 	if entry.Caller.Defined {
-		buf.AppendString(logmgr.ColorTime)
-		buf.AppendString(entry.Caller.TrimmedPath())
-		buf.AppendString(logmgr.ColorReset)
-		buf.AppendString(" ")
+		rec.File = entry.Caller.File
+		rec.Line = entry.Caller.Line
+		rec.Function = entry.Caller.Function
 	}
 
-	buf.AppendString(logmgr.ColorMessage)
-	buf.AppendString(entry.Message)
-	buf.AppendString(logmgr.ColorReset)
-
-	for _, field := range fields {
-		buf.AppendString(" ")
-		field.AddTo(e.Encoder)
+	buf := bufPool.Get()
+	_, _ = buf.Write(e.formatter.Append(nil, &rec))
+	if entry.Stack != "" {
+		buf.AppendString(entry.Stack)
+		buf.AppendString("\n")
 	}
 
-	buf.AppendString("\n")
-
 	return buf, nil
 }
 
-func getLogLevel(level string) zapcore.Level {
+// getLogLevel 将 logmgr 级别转换为 zap 级别，zap 没有 trace 级别，按 debug 处理
+func getLogLevel(level logmgr.Level) zapcore.Level {
 	switch level {
-	case "debug":
+	case logmgr.TraceLevel, logmgr.DebugLevel:
 		return zap.DebugLevel
-	case "info":
+	case logmgr.InfoLevel:
 		return zap.InfoLevel
-	case "warn":
+	case logmgr.WarnLevel:
 		return zap.WarnLevel
-	case "error":
+	case logmgr.ErrorLevel:
 		return zap.ErrorLevel
+	case logmgr.PanicLevel:
+		return zap.PanicLevel
+	case logmgr.FatalLevel:
+		return zap.FatalLevel
 	default:
 		return zap.InfoLevel
 	}
 }
+
+// toLevel 将 zap 级别转换为 logmgr 级别，DPanic 按 panic 处理
+func toLevel(level zapcore.Level) logmgr.Level {
+	switch level {
+	case zapcore.DebugLevel:
+		return logmgr.DebugLevel
+	case zapcore.WarnLevel:
+		return logmgr.WarnLevel
+	case zapcore.ErrorLevel:
+		return logmgr.ErrorLevel
+	case zapcore.DPanicLevel, zapcore.PanicLevel:
+		return logmgr.PanicLevel
+	case zapcore.FatalLevel:
+		return logmgr.FatalLevel
+	default:
+		return logmgr.InfoLevel
+	}
+}
@@ -0,0 +1,37 @@
+package zaplogmgr
+
+import (
+	"testing"
+
+	"github.com/52debug/go-box/log/logmgr"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLevelMapping(t *testing.T) {
+	tests := []struct {
+		level logmgr.Level
+		zap   zapcore.Level
+		back  logmgr.Level // zap 没有 trace，转换回来为 debug
+	}{
+		{logmgr.TraceLevel, zapcore.DebugLevel, logmgr.DebugLevel},
+		{logmgr.DebugLevel, zapcore.DebugLevel, logmgr.DebugLevel},
+		{logmgr.InfoLevel, zapcore.InfoLevel, logmgr.InfoLevel},
+		{logmgr.WarnLevel, zapcore.WarnLevel, logmgr.WarnLevel},
+		{logmgr.ErrorLevel, zapcore.ErrorLevel, logmgr.ErrorLevel},
+		{logmgr.PanicLevel, zapcore.PanicLevel, logmgr.PanicLevel},
+		{logmgr.FatalLevel, zapcore.FatalLevel, logmgr.FatalLevel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			if got := getLogLevel(tt.level); got != tt.zap {
+				t.Fatalf("getLogLevel(%v) = %v，期望 %v", tt.level, got, tt.zap)
+			}
+			if got := toLevel(tt.zap); got != tt.back {
+				t.Fatalf("toLevel(%v) = %v，期望 %v", tt.zap, got, tt.back)
+			}
+		})
+	}
+	if got := toLevel(zapcore.DPanicLevel); got != logmgr.PanicLevel {
+		t.Fatalf("toLevel(dpanic) = %v", got)
+	}
+}
@@ -0,0 +1,48 @@
+package zaplogmgr
+
+import (
+	"context"
+
+	"github.com/52debug/go-box/log/logbridge"
+	"github.com/52debug/go-box/log/logmgr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// debugCore 通过 Ctx 得到的 logger 携带 ZapDebugField 时放行 debug 级别的日志
+type debugCore struct {
+	zapcore.Core
+	escalated bool
+}
+
+func (c *debugCore) Enabled(level zapcore.Level) bool {
+	return c.Core.Enabled(level) || (c.escalated && level >= zapcore.DebugLevel)
+}
+
+func (c *debugCore) With(fields []zapcore.Field) zapcore.Core {
+	return &debugCore{
+		Core:      c.Core.With(fields),
+		escalated: c.escalated || logbridge.HasZapDebugField(fields),
+	}
+}
+
+func (c *debugCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Core.Enabled(ent.Level) {
+		return c.Core.Check(ent, ce)
+	}
+	if c.escalated && ent.Level >= zapcore.DebugLevel {
+		// 直接写入，跳过各输出自身的级别判断
+		return ce.AddCore(ent, c.Core)
+	}
+	return ce
+}
+
+// Ctx 返回用于 ctx 的全局 logger，ctx 通过 logmgr.WithDebug 开启 debug 时输出 debug 日志
+//
+// 需要在配置中开启 ContextDebug。
+func Ctx(ctx context.Context) *zap.Logger {
+	if logmgr.DebugEnabled(ctx) {
+		return zap.L().With(logbridge.ZapDebugField)
+	}
+	return zap.L()
+}
@@ -0,0 +1,161 @@
+package zaplogmgr
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/52debug/go-box/log/logmgr"
+	"go.uber.org/zap/zapcore"
+)
+
+// fieldEncoder 将字段编码为 key=value 形式，嵌套对象和命名空间展开为以点分隔的键
+type fieldEncoder struct {
+	buf      []byte
+	prefix   string
+	timeEnc  logmgr.TimeEncoder // time.Time 字段值按配置的 TimeFormat 和 TimeZone 编码
+	appendKV func(buf []byte, key, value string) []byte
+}
+
+func newFieldEncoder(timeEnc logmgr.TimeEncoder, appendKV func(buf []byte, key, value string) []byte) *fieldEncoder {
+	return &fieldEncoder{timeEnc: timeEnc, appendKV: appendKV}
+}
+
+func (e *fieldEncoder) clone() *fieldEncoder {
+	c := *e
+	c.buf = append([]byte(nil), e.buf...)
+	return &c
+}
+
+func (e *fieldEncoder) add(key, value string) {
+	e.buf = e.appendKV(e.buf, e.prefix+key, value)
+}
+
+func (e *fieldEncoder) AddArray(key string, marshaler zapcore.ArrayMarshaler) error {
+	arr := &sliceEncoder{timeEnc: e.timeEnc}
+	err := marshaler.MarshalLogArray(arr)
+	e.add(key, arr.String())
+	return err
+}
+
+func (e *fieldEncoder) AddObject(key string, marshaler zapcore.ObjectMarshaler) error {
+	prefix := e.prefix
+	e.prefix = prefix + key + "."
+	err := marshaler.MarshalLogObject(e)
+	e.prefix = prefix
+	return err
+}
+
+func (e *fieldEncoder) AddBinary(key string, value []byte) {
+	e.add(key, base64.StdEncoding.EncodeToString(value))
+}
+
+func (e *fieldEncoder) AddByteString(key string, value []byte) { e.add(key, string(value)) }
+func (e *fieldEncoder) AddBool(key string, value bool)         { e.add(key, strconv.FormatBool(value)) }
+func (e *fieldEncoder) AddComplex128(key string, value complex128) {
+	e.add(key, strconv.FormatComplex(value, 'g', -1, 128))
+}
+func (e *fieldEncoder) AddComplex64(key string, value complex64) {
+	e.add(key, strconv.FormatComplex(complex128(value), 'g', -1, 64))
+}
+func (e *fieldEncoder) AddDuration(key string, value time.Duration) { e.add(key, value.String()) }
+func (e *fieldEncoder) AddFloat64(key string, value float64) {
+	e.add(key, strconv.FormatFloat(value, 'g', -1, 64))
+}
+func (e *fieldEncoder) AddFloat32(key string, value float32) {
+	e.add(key, strconv.FormatFloat(float64(value), 'g', -1, 32))
+}
+func (e *fieldEncoder) AddInt(key string, value int)         { e.AddInt64(key, int64(value)) }
+func (e *fieldEncoder) AddInt64(key string, value int64)     { e.add(key, strconv.FormatInt(value, 10)) }
+func (e *fieldEncoder) AddInt32(key string, value int32)     { e.AddInt64(key, int64(value)) }
+func (e *fieldEncoder) AddInt16(key string, value int16)     { e.AddInt64(key, int64(value)) }
+func (e *fieldEncoder) AddInt8(key string, value int8)       { e.AddInt64(key, int64(value)) }
+func (e *fieldEncoder) AddString(key, value string)          { e.add(key, value) }
+func (e *fieldEncoder) AddTime(key string, value time.Time)  { e.add(key, e.timeEnc.Format(value)) }
+func (e *fieldEncoder) AddUint(key string, value uint)       { e.AddUint64(key, uint64(value)) }
+func (e *fieldEncoder) AddUint64(key string, value uint64)   { e.add(key, strconv.FormatUint(value, 10)) }
+func (e *fieldEncoder) AddUint32(key string, value uint32)   { e.AddUint64(key, uint64(value)) }
+func (e *fieldEncoder) AddUint16(key string, value uint16)   { e.AddUint64(key, uint64(value)) }
+func (e *fieldEncoder) AddUint8(key string, value uint8)     { e.AddUint64(key, uint64(value)) }
+func (e *fieldEncoder) AddUintptr(key string, value uintptr) { e.AddUint64(key, uint64(value)) }
+
+func (e *fieldEncoder) AddReflected(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		e.add(key, fmt.Sprint(value))
+		return err
+	}
+	e.add(key, string(data))
+	return nil
+}
+
+func (e *fieldEncoder) OpenNamespace(key string) {
+	e.prefix += key + "."
+}
+
+// sliceEncoder 将数组编码为 [a,b,c] 形式
+type sliceEncoder struct {
+	elems   []string
+	timeEnc logmgr.TimeEncoder
+}
+
+func (s *sliceEncoder) String() string {
+	return "[" + strings.Join(s.elems, ",") + "]"
+}
+
+func (s *sliceEncoder) append(v string) { s.elems = append(s.elems, v) }
+
+func (s *sliceEncoder) AppendArray(marshaler zapcore.ArrayMarshaler) error {
+	arr := &sliceEncoder{timeEnc: s.timeEnc}
+	err := marshaler.MarshalLogArray(arr)
+	s.append(arr.String())
+	return err
+}
+
+func (s *sliceEncoder) AppendObject(marshaler zapcore.ObjectMarshaler) error {
+	m := zapcore.NewMapObjectEncoder()
+	err := marshaler.MarshalLogObject(m)
+	data, _ := json.Marshal(m.Fields)
+	s.append(string(data))
+	return err
+}
+
+func (s *sliceEncoder) AppendReflected(value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		s.append(fmt.Sprint(value))
+		return err
+	}
+	s.append(string(data))
+	return nil
+}
+
+func (s *sliceEncoder) AppendBool(v bool)         { s.append(strconv.FormatBool(v)) }
+func (s *sliceEncoder) AppendByteString(v []byte) { s.append(string(v)) }
+func (s *sliceEncoder) AppendComplex128(v complex128) {
+	s.append(strconv.FormatComplex(v, 'g', -1, 128))
+}
+func (s *sliceEncoder) AppendComplex64(v complex64) {
+	s.append(strconv.FormatComplex(complex128(v), 'g', -1, 64))
+}
+func (s *sliceEncoder) AppendDuration(v time.Duration) { s.append(v.String()) }
+func (s *sliceEncoder) AppendFloat64(v float64)        { s.append(strconv.FormatFloat(v, 'g', -1, 64)) }
+func (s *sliceEncoder) AppendFloat32(v float32) {
+	s.append(strconv.FormatFloat(float64(v), 'g', -1, 32))
+}
+func (s *sliceEncoder) AppendInt(v int)         { s.AppendInt64(int64(v)) }
+func (s *sliceEncoder) AppendInt64(v int64)     { s.append(strconv.FormatInt(v, 10)) }
+func (s *sliceEncoder) AppendInt32(v int32)     { s.AppendInt64(int64(v)) }
+func (s *sliceEncoder) AppendInt16(v int16)     { s.AppendInt64(int64(v)) }
+func (s *sliceEncoder) AppendInt8(v int8)       { s.AppendInt64(int64(v)) }
+func (s *sliceEncoder) AppendString(v string)   { s.append(v) }
+func (s *sliceEncoder) AppendTime(v time.Time)  { s.append(s.timeEnc.Format(v)) }
+func (s *sliceEncoder) AppendUint(v uint)       { s.AppendUint64(uint64(v)) }
+func (s *sliceEncoder) AppendUint64(v uint64)   { s.append(strconv.FormatUint(v, 10)) }
+func (s *sliceEncoder) AppendUint32(v uint32)   { s.AppendUint64(uint64(v)) }
+func (s *sliceEncoder) AppendUint16(v uint16)   { s.AppendUint64(uint64(v)) }
+func (s *sliceEncoder) AppendUint8(v uint8)     { s.AppendUint64(uint64(v)) }
+func (s *sliceEncoder) AppendUintptr(v uintptr) { s.AppendUint64(uint64(v)) }
@@ -0,0 +1,60 @@
+package zaplogmgr
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/52debug/go-box/log/logmgr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestFieldEncoderTime(t *testing.T) {
+	ts := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	tests := []struct {
+		name   string
+		config logmgr.LogConfig
+		want   string // 字段值
+	}{
+		{name: "default", config: logmgr.LogConfig{TimeZone: "UTC"}, want: "2024-05-06 07:08:09.000"},
+		{name: "rfc3339 zone", config: logmgr.LogConfig{TimeFormat: "rfc3339", TimeZone: "Asia/Shanghai"}, want: "2024-05-06T15:08:09+08:00"},
+		{name: "unix", config: logmgr.LogConfig{TimeFormat: "unix"}, want: "1714979289"},
+		{name: "layout", config: logmgr.LogConfig{TimeFormat: "15:04", TimeZone: "UTC"}, want: "07:08"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeEnc, err := tt.config.NewTimeEncoder()
+			if err != nil {
+				t.Skip(err)
+			}
+			cfg := newEncoderConfig(tt.config)
+			cfg.TimeKey = "" // 只比较字段中的时间
+			encoders := map[string]zapcore.Encoder{
+				"logfmt": newLogfmtEncoder(cfg, timeEnc),
+				"color":  newColoredConsoleEncoder(mustFormatter(t, "{fields}"), timeEnc),
+			}
+			for name, enc := range encoders {
+				buf, err := enc.EncodeEntry(zapcore.Entry{Time: ts, Message: "m"}, []zap.Field{
+					zap.Time("at", ts),
+					zap.Times("ats", []time.Time{ts}),
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if line := buf.String(); strings.Count(line, tt.want) != 2 {
+					t.Fatalf("%s 输出 %q，期望 at 和 ats 均为 %q", name, line, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func mustFormatter(t *testing.T, template string) *logmgr.Formatter {
+	t.Helper()
+	f, err := logmgr.NewFormatter(template, logmgr.Theme{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
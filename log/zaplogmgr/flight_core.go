@@ -0,0 +1,62 @@
+package zaplogmgr
+
+import (
+	"github.com/52debug/go-box/log/logbridge"
+	"github.com/52debug/go-box/log/logmgr"
+	"go.uber.org/zap/zapcore"
+)
+
+// flightCore 将不低于 level 的日志编码到飞行记录器
+type flightCore struct {
+	enc   zapcore.Encoder
+	fr    *logmgr.FlightRecorder
+	level zapcore.Level
+	live  zapcore.LevelEnabler // 实时输出的级别，nil 表示没有实时输出
+	debug bool                 // 开启了 ContextDebug
+	// escalated 通过 Ctx 得到的 logger 携带 ZapDebugField，debug 日志同时写入实时输出
+	escalated bool
+}
+
+func (c *flightCore) Enabled(level zapcore.Level) bool {
+	return level >= c.level
+}
+
+func (c *flightCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	c2 := *c
+	c2.enc = enc
+	c2.escalated = c.escalated || (c.debug && logbridge.HasZapDebugField(fields))
+	return &c2
+}
+
+func (c *flightCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *flightCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	c.fr.Add(toLevel(ent.Level), buf.Bytes(), c.isLive(ent.Level))
+	buf.Free()
+	return nil
+}
+
+// isLive 返回该级别的日志是否同时写入了实时输出
+func (c *flightCore) isLive(level zapcore.Level) bool {
+	if c.live == nil {
+		return false
+	}
+	return c.live.Enabled(level) || (c.escalated && level >= zapcore.DebugLevel)
+}
+
+func (c *flightCore) Sync() error {
+	return nil
+}
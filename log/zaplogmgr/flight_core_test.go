@@ -0,0 +1,126 @@
+package zaplogmgr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/52debug/go-box/log/logmgr"
+	"github.com/52debug/go-box/log/zerologmgr"
+	zlog "github.com/rs/zerolog/log"
+	"go.uber.org/zap"
+)
+
+func TestFlightRecorder(t *testing.T) {
+	tests := []struct {
+		name   string
+		config logmgr.LogConfig
+		dump   bool     // 转储到单独的文件
+		output []string // 日志输出的消息
+		dumped []string // 转储文件中的消息
+	}{
+		{
+			// 转储到日志输出时只转储没有写入实时输出的记录，写在触发转储的记录之前
+			name:   "dump to sink",
+			output: []string{"i1", "i1", "d1", "d2", "boom"},
+		},
+		{
+			// 为请求开启的 debug 日志已经写入实时输出，不再转储
+			name:   "context debug",
+			config: logmgr.LogConfig{ContextDebug: true},
+			output: []string{"i1", "d2", "i1", "d1", "boom"},
+		},
+		{
+			name:   "level",
+			config: logmgr.LogConfig{FlightRecorderLevel: "info"},
+			output: []string{"i1", "i1", "boom"},
+		},
+		{
+			// 被采样丢弃的记录仍然保留
+			name:   "sampling",
+			config: logmgr.LogConfig{SamplingInitial: 1},
+			dump:   true,
+			output: []string{"i1", "boom"},
+			dumped: []string{"i1", "d1", "d2", "i1", "boom"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.config
+			c.Level = "info"
+			c.Output = "memory"
+			c.FlightRecorderSize = 10
+			if tt.dump {
+				c.FlightRecorderFile = filepath.Join(t.TempDir(), "flight.log")
+			}
+			logmgr.ResetCapture()
+			Setup(c)
+
+			zap.L().Info("i1")
+			zap.L().Debug("d1")
+			Ctx(logmgr.WithDebug(context.Background())).Debug("d2")
+			zap.L().Info("i1")
+			zap.L().Error("boom")
+
+			var got []string
+			for _, e := range logmgr.CapturedEntries() {
+				got = append(got, e.Message)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.output, ",") {
+				t.Fatalf("输出 %q，期望 %q", got, tt.output)
+			}
+			if tt.dump {
+				if got := dumpedMessages(t, c.FlightRecorderFile); strings.Join(got, ",") != strings.Join(tt.dumped, ",") {
+					t.Fatalf("转储 %q，期望 %q", got, tt.dumped)
+				}
+			}
+		})
+	}
+}
+
+// 开启飞行记录器时处理器不限制级别，zerolog 中按请求开启的 debug 事件仍然写入实时输出
+func TestFlightRecorderZerologDebug(t *testing.T) {
+	for _, size := range []int{0, 10} {
+		logmgr.ResetCapture()
+		Setup(logmgr.LogConfig{Level: "info", Output: "memory", ContextDebug: true, FlightRecorderSize: size})
+
+		ctx := logmgr.WithDebug(context.Background())
+		zlog.Debug().Ctx(ctx).Msg("d1")
+		zerologmgr.Ctx(ctx).Debug().Msg("d2")
+		zlog.Debug().Msg("d3")
+
+		var got []string
+		for _, e := range logmgr.CapturedEntries() {
+			got = append(got, e.Message)
+			for k := range e.Fields {
+				if strings.HasPrefix(k, "_logmgr_debug") {
+					t.Fatalf("输出了标记字段 %q", k)
+				}
+			}
+		}
+		if strings.Join(got, ",") != "d1,d2" {
+			t.Fatalf("FlightRecorderSize=%d 时输出 %q", size, got)
+		}
+	}
+}
+
+// dumpedMessages 返回转储文件中每条记录的消息
+func dumpedMessages(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser, _ := logmgr.LogConfig{}.NewEntryParser()
+	var msgs []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		e, err := parser.Parse([]byte(line))
+		if err != nil {
+			t.Fatalf("无法解析转储的记录 %q: %v", line, err)
+		}
+		msgs = append(msgs, e.Message)
+	}
+	return msgs
+}
@@ -0,0 +1,88 @@
+package zaplogmgr
+
+import (
+	"github.com/52debug/go-box/log/logmgr"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// logfmtEncoder 输出 logfmt 格式，对象与命名空间展开为以点分隔的键
+type logfmtEncoder struct {
+	*fieldEncoder
+	cfg zapcore.EncoderConfig
+}
+
+func newLogfmtEncoder(cfg zapcore.EncoderConfig, timeEnc logmgr.TimeEncoder) zapcore.Encoder {
+	return &logfmtEncoder{
+		fieldEncoder: newFieldEncoder(timeEnc, logmgr.AppendLogfmt),
+		cfg:          cfg,
+	}
+}
+
+func (e *logfmtEncoder) Clone() zapcore.Encoder {
+	return &logfmtEncoder{
+		fieldEncoder: e.fieldEncoder.clone(),
+		cfg:          e.cfg,
+	}
+}
+
+func (e *logfmtEncoder) EncodeEntry(entry zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	var line []byte
+	cfg := e.cfg
+
+	if cfg.TimeKey != "" && cfg.EncodeTime != nil {
+		line = logmgr.AppendLogfmt(line, cfg.TimeKey, encodePrimitive(func(enc zapcore.PrimitiveArrayEncoder) {
+			cfg.EncodeTime(entry.Time, enc)
+		}))
+	}
+	if cfg.LevelKey != "" && cfg.EncodeLevel != nil {
+		line = logmgr.AppendLogfmt(line, cfg.LevelKey, encodePrimitive(func(enc zapcore.PrimitiveArrayEncoder) {
+			cfg.EncodeLevel(entry.Level, enc)
+		}))
+	}
+	if cfg.NameKey != "" && entry.LoggerName != "" {
+		line = logmgr.AppendLogfmt(line, cfg.NameKey, entry.LoggerName)
+	}
+	if entry.Caller.Defined {
+		if cfg.CallerKey != "" && cfg.EncodeCaller != nil {
+			line = logmgr.AppendLogfmt(line, cfg.CallerKey, encodePrimitive(func(enc zapcore.PrimitiveArrayEncoder) {
+				cfg.EncodeCaller(entry.Caller, enc)
+			}))
+		}
+		if cfg.FunctionKey != "" {
+			line = logmgr.AppendLogfmt(line, cfg.FunctionKey, entry.Caller.Function)
+		}
+	}
+	if cfg.MessageKey != "" {
+		line = logmgr.AppendLogfmt(line, cfg.MessageKey, entry.Message)
+	}
+
+	enc := e.fieldEncoder.clone()
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+	if len(enc.buf) > 0 {
+		if len(line) > 0 {
+			line = append(line, ' ')
+		}
+		line = append(line, enc.buf...)
+	}
+	if cfg.StacktraceKey != "" && entry.Stack != "" {
+		line = logmgr.AppendLogfmt(line, cfg.StacktraceKey, entry.Stack)
+	}
+
+	buf := bufPool.Get()
+	_, _ = buf.Write(line)
+	buf.AppendString("\n")
+	return buf, nil
+}
+
+// encodePrimitive 通过 EncoderConfig 中的编码函数得到文本
+func encodePrimitive(fn func(enc zapcore.PrimitiveArrayEncoder)) string {
+	enc := &sliceEncoder{}
+	fn(enc)
+	if len(enc.elems) == 0 {
+		return ""
+	}
+	return enc.elems[0]
+}
@@ -0,0 +1,43 @@
+package zaplogmgr
+
+import (
+	"github.com/52debug/go-box/log/logmgr"
+	"go.uber.org/zap/zapcore"
+)
+
+// metricsCore 按级别和模块统计输出的日志，模块优先使用 logger 名称，其次为 module 字段
+type metricsCore struct {
+	zapcore.Core
+	module string
+}
+
+func (c *metricsCore) With(fields []zapcore.Field) zapcore.Core {
+	module := c.module
+	for _, f := range fields {
+		if f.Key == logmgr.ModuleKey && f.Type == zapcore.StringType {
+			module = f.String
+		}
+	}
+	return &metricsCore{Core: c.Core.With(fields), module: module}
+}
+
+func (c *metricsCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *metricsCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	module := ent.LoggerName
+	if module == "" {
+		module = c.module
+		for _, f := range fields {
+			if f.Key == logmgr.ModuleKey && f.Type == zapcore.StringType {
+				module = f.String
+			}
+		}
+	}
+	logmgr.CountRecord(toLevel(ent.Level), module)
+	return c.Core.Write(ent, fields)
+}
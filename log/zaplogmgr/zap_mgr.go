@@ -1,69 +1,198 @@
 package zaplogmgr
 
 import (
+	"io"
 	"time"
 
+	"github.com/52debug/go-box/log/logbridge"
 	"github.com/52debug/go-box/log/logmgr"
 	"github.com/mattn/go-colorable"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 func Setup(config logmgr.LogConfig) {
-	level := getLogLevel(config.Level)
+	config = config.Checked()
+	level := getLogLevel(config.GetLevel())
 
 	var cores []zapcore.Core
+	var fileWriter io.Writer
 
-	if config.Output == "console" || config.Output == "both" {
-		consoleEncoder := newColoredConsoleEncoder()
-		consoleCore := zapcore.NewCore(consoleEncoder, zapcore.AddSync(colorable.NewColorableStdout()), level)
+	if config.ConsoleEnabled() {
+		consoleEncoder := newConsoleEncoder(config)
+		consoleCore := zapcore.NewCore(consoleEncoder, zapcore.AddSync(config.WrapSink(logmgr.SinkConsole, colorable.NewColorableStdout())), level)
 		cores = append(cores, consoleCore)
 	}
 
-	if config.Output == "file" || config.Output == "both" {
-		lumberjackLogger := &lumberjack.Logger{
-			Filename:   config.FilePath,
-			MaxSize:    config.MaxSize,
-			MaxBackups: config.MaxBackups,
-			MaxAge:     config.MaxAge,
-			Compress:   config.Compress,
+	if config.GetSink() != "" {
+		fileWriter = newFileWriter(config)
+		fileEncoder := newEncoder(config, config.FileEncoding)
+		fileCore := zapcore.NewCore(fileEncoder, zapcore.AddSync(fileWriter), level)
+		cores = append(cores, fileCore)
+	}
+
+	if config.Metrics && len(cores) > 0 {
+		cores = []zapcore.Core{&metricsCore{Core: zapcore.NewTee(cores...)}}
+	}
+	if config.ContextDebug && len(cores) > 0 {
+		cores = []zapcore.Core{&debugCore{Core: zapcore.NewTee(cores...)}}
+	}
+
+	var core zapcore.Core
+	if len(cores) > 0 {
+		core = zapcore.NewTee(cores...)
+		if config.SamplingInitial > 0 {
+			var opts []zapcore.SamplerOption
+			if config.Metrics {
+				opts = append(opts, zapcore.SamplerHook(func(ent zapcore.Entry, dec zapcore.SamplingDecision) {
+					if dec&zapcore.LogDropped != 0 {
+						logmgr.CountSampled(toLevel(ent.Level))
+					}
+				}))
+			}
+			core = zapcore.NewSamplerWithOptions(core, time.Second, config.SamplingInitial, max(config.SamplingThereafter, 0), opts...)
 		}
-		// 时间格式
-		timeEncoder := func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
-			enc.AppendString(t.Format("2006-01-02 15:04:05.000"))
+	}
+
+	// 飞行记录器不受日志级别限制，位于采样之前；排在其他输出之前，error 记录触发的转储写在该记录之前
+	fr, err := config.NewFlightRecorder(fileWriter)
+	if err != nil {
+		panic("创建飞行记录器失败: " + err.Error())
+	}
+	logmgr.SetFlightRecorder(fr)
+	if fr != nil {
+		fc := &flightCore{
+			enc:   newEncoder(config, config.FileEncoding),
+			fr:    fr,
+			level: getLogLevel(config.GetFlightRecorderLevel()),
+			debug: config.ContextDebug,
+		}
+		if core == nil {
+			core = fc
+		} else {
+			fc.live = level
+			core = zapcore.NewTee(fc, core)
 		}
-		fileEncoderConfig := zap.NewProductionEncoderConfig()
-		fileEncoderConfig.TimeKey = "time"
-		fileEncoderConfig.EncodeTime = timeEncoder
-		fileEncoder := zapcore.NewJSONEncoder(fileEncoderConfig)
-		fileCore := zapcore.NewCore(fileEncoder, zapcore.AddSync(lumberjackLogger), level)
-		cores = append(cores, fileCore)
 	}
 
-	if len(cores) == 0 {
+	if core == nil {
 		// 创建空 logger
 		nopLogger := zap.NewNop()
-		zap.ReplaceGlobals(nopLogger) // 替换全局 logger
+		logbridge.InstallZap(nopLogger, nil) // 替换全局 logger
+		logmgr.SetSync(nil)
 		return
 	}
 
-	core := zapcore.NewTee(cores...)
-	logger := zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
-	zap.ReplaceGlobals(logger)
+	logger := zap.New(core, getOptions(config)...)
+	// 替换全局 logger，并将标准库 log、slog、zerolog 路由到 zap
+	logbridge.InstallZap(logger, getStackLevel(config))
+	logmgr.SetSync(logger.Sync)
+}
+
+// newFileWriter 创建文件写入器，输出到内存或套接字时返回对应的 writer
+func newFileWriter(config logmgr.LogConfig) io.Writer {
+	switch config.GetSink() {
+	case logmgr.SinkMemory:
+		return config.WrapSink(logmgr.SinkMemory, config.CaptureWriter())
+	case logmgr.SinkSocket:
+		w, err := config.NewSocketWriter()
+		if err != nil {
+			panic("创建日志套接字失败: " + err.Error())
+		}
+		return config.WrapSink(logmgr.SinkSocket, w)
+	}
+	w, err := config.NewFileWriter()
+	if err != nil {
+		panic("创建日志文件失败: " + err.Error())
+	}
+	return config.WrapSink(logmgr.SinkFile, w)
+}
+
+// getOptions 按配置设置调用位置和堆栈
+func getOptions(config logmgr.LogConfig) []zap.Option {
+	var opts []zap.Option
+	if !config.DisableCaller {
+		opts = append(opts, zap.AddCaller())
+	}
+	if stackLevel := getStackLevel(config); stackLevel != nil {
+		opts = append(opts, zap.AddStacktrace(stackLevel))
+	}
+	return opts
+}
+
+// getStackLevel 返回记录堆栈的最低级别，不记录堆栈时返回 nil
+func getStackLevel(config logmgr.LogConfig) zapcore.LevelEnabler {
+	if stackLevel, ok := config.GetStacktraceLevel(); ok {
+		return getLogLevel(stackLevel)
+	}
+	return nil
+}
+
+// newConsoleEncoder 创建控制台编码器，默认使用带颜色的模板格式
+func newConsoleEncoder(config logmgr.LogConfig) zapcore.Encoder {
+	switch config.ConsoleEncoding {
+	case logmgr.EncodingJSON, logmgr.EncodingLogfmt:
+		return newEncoder(config, config.ConsoleEncoding)
+	}
+	formatter, err := config.NewFormatter()
+	if err != nil {
+		panic("创建控制台格式失败: " + err.Error())
+	}
+	return newColoredConsoleEncoder(formatter, newTimeEncoder(config))
 }
 
-func getLevelColor(level zapcore.Level) string {
-	switch level {
-	case zapcore.DebugLevel:
-		return logmgr.ColorSource
-	case zapcore.InfoLevel:
-		return logmgr.ColorInfo
-	case zapcore.WarnLevel:
-		return logmgr.ColorWarn
-	case zapcore.ErrorLevel:
-		return logmgr.ColorError
-	default:
-		return logmgr.ColorReset
+// newEncoder 创建 JSON 或 logfmt 编码器
+func newEncoder(config logmgr.LogConfig, encoding string) zapcore.Encoder {
+	encoderConfig := newEncoderConfig(config)
+	if encoding == logmgr.EncodingLogfmt {
+		return newLogfmtEncoder(encoderConfig, newTimeEncoder(config))
+	}
+	return zapcore.NewJSONEncoder(encoderConfig)
+}
+
+// newTimeEncoder 按配置创建时间编码器
+func newTimeEncoder(config logmgr.LogConfig) logmgr.TimeEncoder {
+	timeEnc, err := config.NewTimeEncoder()
+	if err != nil {
+		panic("日志时间配置无效: " + err.Error())
+	}
+	return timeEnc
+}
+
+// newEncoderConfig 按配置设置时间格式和字段名
+func newEncoderConfig(config logmgr.LogConfig) zapcore.EncoderConfig {
+	timeEnc := newTimeEncoder(config)
+	keys := config.FieldKeys(logmgr.FieldKeys{
+		Time:       "time",
+		Level:      "level",
+		Message:    "msg",
+		Caller:     "caller",
+		Stacktrace: logmgr.StacktraceKey,
+	})
+
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = keys.Time
+	encoderConfig.LevelKey = keys.Level
+	encoderConfig.MessageKey = keys.Message
+	encoderConfig.CallerKey = keys.Caller
+	encoderConfig.StacktraceKey = keys.Stacktrace
+	// 调用位置格式
+	encoderConfig.EncodeCaller = func(caller zapcore.EntryCaller, enc zapcore.PrimitiveArrayEncoder) {
+		enc.AppendString(logmgr.FormatCaller(config.CallerFormat, caller.File, caller.Line, caller.Function))
+	}
+	// Schema 中的级别取值
+	if schema := config.GetSchema(); schema.Levels != nil {
+		encoderConfig.EncodeLevel = func(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+			enc.AppendString(schema.LevelName(toLevel(l)))
+		}
+	}
+	// 时间格式
+	encoderConfig.EncodeTime = func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+		if timeEnc.Numeric() {
+			enc.AppendInt64(timeEnc.Int64(t))
+		} else {
+			enc.AppendString(timeEnc.Format(t))
+		}
 	}
+	return encoderConfig
 }
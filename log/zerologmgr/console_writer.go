@@ -0,0 +1,112 @@
+package zerologmgr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/52debug/go-box/log/logmgr"
+	"github.com/rs/zerolog"
+)
+
+// consoleWriter 解析 zerolog 输出的 JSON，并按模板输出带颜色的文本
+type consoleWriter struct {
+	out       io.Writer
+	formatter *logmgr.Formatter
+	timeEnc   logmgr.TimeEncoder // 用于解析 JSON 中的时间
+	schema    logmgr.Schema      // 用于解析 JSON 中的级别
+}
+
+func newConsoleWriter(out io.Writer, formatter *logmgr.Formatter, timeEnc logmgr.TimeEncoder, schema logmgr.Schema) *consoleWriter {
+	return &consoleWriter{out: out, formatter: formatter, timeEnc: timeEnc, schema: schema}
+}
+
+func (w *consoleWriter) Write(p []byte) (int, error) {
+	evt, err := decodeEvent(p)
+	if err != nil {
+		return 0, err
+	}
+
+	rec := logmgr.Record{Time: time.Now()}
+	if v, ok := evt[zerolog.TimestampFieldName]; ok {
+		if t, err := w.timeEnc.Parse(fieldValue(v)); err == nil {
+			rec.Time = t
+		}
+	}
+	if v, ok := evt[zerolog.LevelFieldName].(string); ok {
+		rec.Level, _ = w.schema.ParseLevel(v)
+	}
+	if v, ok := evt[zerolog.MessageFieldName].(string); ok {
+		rec.Message = v
+	}
+	if v, ok := evt[zerolog.CallerFieldName].(string); ok {
+		if idx := strings.LastIndexByte(v, ':'); idx > 0 {
+			if line, err := strconv.Atoi(v[idx+1:]); err == nil {
+				v, rec.Line = v[:idx], line
+			}
+		}
+		// CallerFormat 为 function 时调用位置是函数名而不是文件
+		if strings.HasSuffix(v, ".go") {
+			rec.File = v
+		} else {
+			rec.Function = v
+		}
+	}
+
+	// 其余字段按名称排序输出，堆栈单独输出在日志行之后
+	stack, _ := evt[stacktraceKey].(string)
+	keys := make([]string, 0, len(evt))
+	for k := range evt {
+		switch k {
+		case zerolog.TimestampFieldName, zerolog.LevelFieldName, zerolog.MessageFieldName, zerolog.CallerFieldName, stacktraceKey:
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		rec.Fields = w.formatter.AppendField(rec.Fields, k, fieldValue(evt[k]))
+	}
+
+	line := w.formatter.Append(nil, &rec)
+	if stack != "" {
+		line = append(append(line, stack...), '\n')
+	}
+	if _, err := w.out.Write(line); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// decodeEvent 解析 zerolog 输出的一行 JSON
+func decodeEvent(p []byte) (map[string]interface{}, error) {
+	var evt map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(p))
+	d.UseNumber()
+	if err := d.Decode(&evt); err != nil {
+		return nil, fmt.Errorf("无法解析日志事件: %w", err)
+	}
+	return evt, nil
+}
+
+// fieldValue 将 JSON 值转换为文本
+func fieldValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
@@ -0,0 +1,38 @@
+package zerologmgr
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/52debug/go-box/log/logmgr"
+)
+
+func TestConsoleWriterCaller(t *testing.T) {
+	tests := []struct {
+		caller string
+		want   string
+	}{
+		{caller: "db/conn.go:42", want: "db/conn.go:42 m\n"},
+		{caller: "/src/app/internal/db/conn.go:42", want: "/src/app/internal/db/conn.go:42 m\n"},
+		// CallerFormat 为 function 时调用位置是函数名
+		{caller: "db.(*Conn).Query:42", want: "db.(*Conn).Query:42 m\n"},
+		{caller: "main.main", want: "main.main m\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.caller, func(t *testing.T) {
+			config := logmgr.LogConfig{Template: "{caller:full} {msg}", Theme: "monochrome"}
+			formatter, err := config.NewFormatter()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			w := newConsoleWriter(&buf, formatter, logmgr.TimeEncoder{}, config.GetSchema())
+			if _, err := w.Write([]byte(`{"level":"info","caller":"` + tt.caller + `","message":"m"}`)); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Fatalf("输出 %q，期望 %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
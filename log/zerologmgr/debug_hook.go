@@ -0,0 +1,37 @@
+package zerologmgr
+
+import (
+	"context"
+
+	"github.com/52debug/go-box/log/logmgr"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// debugHook 丢弃低于配置级别的事件，事件的 context 通过 logmgr.WithDebug 开启 debug 时保留 debug 事件
+type debugHook struct {
+	level zerolog.Level
+}
+
+func (h debugHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	if level < h.level && !(level >= zerolog.DebugLevel && logmgr.DebugEnabled(e.GetCtx())) {
+		e.Discard()
+	}
+}
+
+// debugLogger 开启飞行记录器时为 context 开启 debug 的请求使用的 logger，
+// 未开启 ContextDebug 或未开启飞行记录器时为 nil
+var debugLogger *zerolog.Logger
+
+// Ctx 返回事件携带 ctx 的全局 logger，ctx 通过 logmgr.WithDebug 开启 debug 时输出 debug 日志
+//
+// 需要在配置中开启 ContextDebug。未开启飞行记录器时也可以直接使用 log.Debug().Ctx(ctx)；
+// 开启后低于配置级别的事件只写入飞行记录器，需要通过 Ctx 获取 logger。
+func Ctx(ctx context.Context) *zerolog.Logger {
+	logger := log.Logger
+	if debugLogger != nil && logmgr.DebugEnabled(ctx) {
+		logger = *debugLogger
+	}
+	logger = logger.With().Ctx(ctx).Logger()
+	return &logger
+}
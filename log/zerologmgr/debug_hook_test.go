@@ -0,0 +1,79 @@
+package zerologmgr
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/52debug/go-box/log/logmgr"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+func TestContextDebug(t *testing.T) {
+	tests := []struct {
+		name   string
+		config logmgr.LogConfig
+		output []string // 日志输出的消息
+	}{
+		{
+			name:   "hook",
+			output: []string{"direct", "ctx", "slog", "info"},
+		},
+		{
+			// 开启飞行记录器时直接使用全局 logger 的 debug 事件只写入飞行记录器
+			name:   "flight recorder",
+			config: logmgr.LogConfig{FlightRecorderSize: 10},
+			output: []string{"ctx", "slog", "info"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.config
+			c.Level = "info"
+			c.Output = "memory"
+			c.ContextDebug = true
+			logmgr.ResetCapture()
+			Setup(c)
+
+			ctx := logmgr.WithDebug(context.Background())
+			plain := context.Background()
+			// 字段中包含 JSON 片段时原样输出
+			field := `,"_logmgr_debug":true`
+			log.Debug().Ctx(ctx).Str("raw", field).Msg("direct")
+			Ctx(ctx).Debug().Str("raw", field).Msg("ctx")
+			slog.DebugContext(ctx, "slog", "raw", field)
+			log.Debug().Ctx(plain).Msg("skip")
+			Ctx(plain).Debug().Msg("skip")
+			slog.DebugContext(plain, "skip")
+			log.Trace().Ctx(ctx).Msg("skip")
+			Ctx(ctx).Info().Str("raw", field).Msg("info")
+
+			var got []string
+			for _, e := range logmgr.CapturedEntries() {
+				got = append(got, e.Message)
+				if raw := e.Fields["raw"]; raw != field {
+					t.Fatalf("%s 的 raw 字段 %q", e.Message, raw)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.output, ",") {
+				t.Fatalf("输出 %q，期望 %q", got, tt.output)
+			}
+			if got := log.Logger.GetLevel(); got > zerolog.DebugLevel {
+				t.Fatalf("全局 logger 级别 %v", got)
+			}
+		})
+	}
+}
+
+func TestLoggerLevel(t *testing.T) {
+	logmgr.ResetCapture()
+	Setup(logmgr.LogConfig{Level: "warn", Output: "memory"})
+	if got := zerolog.GlobalLevel(); got != zerolog.WarnLevel {
+		t.Fatalf("全局级别 %v", got)
+	}
+	if got := log.Logger.GetLevel(); got != zerolog.WarnLevel {
+		t.Fatalf("logger 级别 %v", got)
+	}
+}
@@ -0,0 +1,77 @@
+package zerologmgr
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/52debug/go-box/log/logmgr"
+	"github.com/rs/zerolog"
+)
+
+// flightWriter 将不低于 level 的事件写入飞行记录器
+type flightWriter struct {
+	fr     *logmgr.FlightRecorder
+	level  zerolog.Level
+	live   zerolog.Level // 不低于该级别的事件同时写入实时输出
+	logfmt bool          // 转换为 logfmt 后保存
+}
+
+func (w flightWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+func (w flightWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if level < w.level {
+		return len(p), nil
+	}
+	line := p
+	if w.logfmt {
+		var buf bytes.Buffer
+		if _, err := newLogfmtWriter(&buf).Write(line); err != nil {
+			return 0, err
+		}
+		line = buf.Bytes()
+	}
+	w.fr.Add(toLevel(level), line, level >= w.live)
+	return len(p), nil
+}
+
+// levelWriter 只输出不低于 level 的事件，用于飞行记录器记录低于配置级别的事件时仍按配置级别输出
+type levelWriter struct {
+	out   io.Writer
+	level zerolog.Level
+}
+
+func (w levelWriter) Write(p []byte) (int, error) {
+	return w.out.Write(p)
+}
+
+func (w levelWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if level < w.level {
+		return len(p), nil
+	}
+	if lw, ok := w.out.(zerolog.LevelWriter); ok {
+		return lw.WriteLevel(level, p)
+	}
+	return w.out.Write(p)
+}
+
+// toLevel 将 zerolog 级别转换为 logmgr 级别，无级别的事件按 info 处理
+func toLevel(level zerolog.Level) logmgr.Level {
+	switch level {
+	case zerolog.TraceLevel:
+		return logmgr.TraceLevel
+	case zerolog.DebugLevel:
+		return logmgr.DebugLevel
+	case zerolog.WarnLevel:
+		return logmgr.WarnLevel
+	case zerolog.ErrorLevel:
+		return logmgr.ErrorLevel
+	case zerolog.PanicLevel:
+		return logmgr.PanicLevel
+	case zerolog.FatalLevel:
+		return logmgr.FatalLevel
+	default:
+		return logmgr.InfoLevel
+	}
+}
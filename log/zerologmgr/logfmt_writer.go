@@ -0,0 +1,64 @@
+package zerologmgr
+
+import (
+	"io"
+	"sort"
+
+	"github.com/52debug/go-box/log/logmgr"
+	"github.com/rs/zerolog"
+)
+
+// logfmtWriter 将 zerolog 输出的 JSON 转换为 logfmt，嵌套对象展开为以点分隔的键
+type logfmtWriter struct {
+	out io.Writer
+}
+
+func newLogfmtWriter(out io.Writer) *logfmtWriter {
+	return &logfmtWriter{out: out}
+}
+
+func (w *logfmtWriter) Write(p []byte) (int, error) {
+	evt, err := decodeEvent(p)
+	if err != nil {
+		return 0, err
+	}
+
+	var line []byte
+	// 内置字段在前
+	builtin := []string{
+		zerolog.TimestampFieldName,
+		zerolog.LevelFieldName,
+		zerolog.CallerFieldName,
+		zerolog.MessageFieldName,
+	}
+	for _, k := range builtin {
+		if v, ok := evt[k]; ok {
+			line = logmgr.AppendLogfmt(line, k, fieldValue(v))
+			delete(evt, k)
+		}
+	}
+	line = appendFlattened(line, "", evt)
+	line = append(line, '\n')
+
+	if _, err := w.out.Write(line); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// appendFlattened 按键名排序追加字段，嵌套对象展开为 prefix.key
+func appendFlattened(line []byte, prefix string, m map[string]interface{}) []byte {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if sub, ok := m[k].(map[string]interface{}); ok && len(sub) > 0 {
+			line = appendFlattened(line, prefix+k+".", sub)
+			continue
+		}
+		line = logmgr.AppendLogfmt(line, prefix+k, fieldValue(m[k]))
+	}
+	return line
+}
@@ -0,0 +1,50 @@
+package zerologmgr
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+
+	"github.com/52debug/go-box/log/logmgr"
+	"github.com/rs/zerolog"
+)
+
+// moduleField JSON 中 module 字段的开头
+var moduleField = []byte(`"` + logmgr.ModuleKey + `":"`)
+
+// metricsWriter 按级别和模块统计写入的事件，模块取 module 字段
+type metricsWriter struct {
+	out io.Writer
+}
+
+func (w metricsWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+func (w metricsWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	logmgr.CountRecord(toLevel(level), eventModule(p))
+	return w.out.Write(p)
+}
+
+// eventModule 从 JSON 事件中取出 module 字段，不完整解析 JSON
+func eventModule(p []byte) string {
+	idx := bytes.Index(p, moduleField)
+	if idx < 0 {
+		return ""
+	}
+	rest := p[idx+len(moduleField)-1:]
+	// 找到未转义的结束引号
+	for i := 1; i < len(rest); i++ {
+		switch rest[i] {
+		case '\\':
+			i++
+		case '"':
+			s, err := strconv.Unquote(string(rest[:i+1]))
+			if err != nil {
+				return string(rest[1:i])
+			}
+			return s
+		}
+	}
+	return ""
+}
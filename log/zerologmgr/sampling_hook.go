@@ -0,0 +1,17 @@
+package zerologmgr
+
+import (
+	"github.com/52debug/go-box/log/logmgr"
+	"github.com/rs/zerolog"
+)
+
+// samplingHook 按级别和消息采样，丢弃未被采样的事件
+type samplingHook struct {
+	sampler *logmgr.Sampler
+}
+
+func (h samplingHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	if level < zerolog.NoLevel && !h.sampler.Allow(toLevel(level), msg) {
+		e.Discard()
+	}
+}
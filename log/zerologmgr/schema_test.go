@@ -0,0 +1,64 @@
+package zerologmgr
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/52debug/go-box/log/logmgr"
+	"github.com/rs/zerolog/log"
+)
+
+func TestSchema(t *testing.T) {
+	tests := []struct {
+		schema string
+		keys   [4]string // 时间、级别、消息、调用位置的字段名
+		levels [2]string // warn、error 的输出值
+	}{
+		{
+			schema: logmgr.SchemaECS,
+			keys:   [4]string{"@timestamp", "log.level", "message", "log.origin.file.name"},
+			levels: [2]string{"warn", "error"},
+		},
+		{
+			schema: logmgr.SchemaGCP,
+			keys:   [4]string{"time", "severity", "message", "caller"},
+			levels: [2]string{"WARNING", "ERROR"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.schema, func(t *testing.T) {
+			c, capture := logmgr.Test()
+			c.Schema = tt.schema
+			c.StacktraceLevel = "none"
+			Setup(c)
+			log.Warn().Msg("hello")
+			log.Error().Msg("hello")
+
+			logs := capture.Logs()
+			if len(logs) != 2 {
+				t.Fatalf("输出 %d 条日志", len(logs))
+			}
+			for i, line := range logs {
+				var m map[string]interface{}
+				if err := json.Unmarshal([]byte(line), &m); err != nil {
+					t.Fatalf("无法解析 %q: %v", line, err)
+				}
+				// 未配置 TimeFormat 时使用 UTC 的 RFC 3339
+				ts, _ := m[tt.keys[0]].(string)
+				if _, err := time.Parse(time.RFC3339Nano, ts); err != nil || !strings.HasSuffix(ts, "Z") {
+					t.Fatalf("时间 %q 不是 UTC 的 RFC 3339: %s", ts, line)
+				}
+				if m[tt.keys[1]] != tt.levels[i] || m[tt.keys[2]] != "hello" || m[tt.keys[3]] == nil {
+					t.Fatalf("字段名或级别错误: %s", line)
+				}
+				for _, native := range []string{"level", "msg"} {
+					if _, ok := m[native]; ok {
+						t.Fatalf("输出了原生字段 %s: %s", native, line)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package zerologmgr
+
+import (
+	"github.com/52debug/go-box/log/logmgr"
+	"github.com/rs/zerolog"
+)
+
+// 记录堆栈时跳过的日志库内部栈帧
+var stackSkipPrefixes = []string{
+	"github.com/52debug/go-box/log/zerologmgr.",
+	"github.com/rs/zerolog.",
+	"github.com/rs/zerolog/log.",
+	"github.com/52debug/go-box/log/logbridge.",
+	"log/slog.",
+	"log.",
+	"go.uber.org/zap",
+}
+
+// stacktraceKey 堆栈字段名，由 Setup 按配置设置
+var stacktraceKey = logmgr.StacktraceKey
+
+// stackHook 为达到指定级别的事件附加调用栈，事件的 context 中携带调用栈时优先使用
+type stackHook struct {
+	level   zerolog.Level
+	enabled bool
+}
+
+// newStackHook 按配置创建堆栈钩子
+func newStackHook(config logmgr.LogConfig) stackHook {
+	level, ok := config.GetStacktraceLevel()
+	return stackHook{level: getLogLevel(level), enabled: ok}
+}
+
+func (h stackHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	if stack, ok := logmgr.StacktraceFromContext(e.GetCtx()); ok {
+		if stack != "" {
+			e.Str(stacktraceKey, stack)
+		}
+		return
+	}
+	if h.enabled && level >= h.level && level < zerolog.NoLevel {
+		e.Str(stacktraceKey, logmgr.Stacktrace(0, stackSkipPrefixes...))
+	}
+}
@@ -2,76 +2,238 @@ package zerologmgr
 
 import (
 	"io"
+	"log/slog"
 	"os"
-	"path/filepath"
+	"runtime"
+	"time"
 
+	"github.com/52debug/go-box/log/logbridge"
 	"github.com/52debug/go-box/log/logmgr"
+	"github.com/mattn/go-colorable"
 	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 func Setup(config logmgr.LogConfig) {
+	config = config.Checked()
 	// 设置全局日志级别
-	var level zerolog.Level
-	switch config.Level {
-	case "debug":
-		level = zerolog.DebugLevel
-	case "info":
-		level = zerolog.InfoLevel
-	case "warn":
-		level = zerolog.WarnLevel
-	case "error":
-		level = zerolog.ErrorLevel
-	default:
-		level = zerolog.InfoLevel
-	}
+	level := getLogLevel(config.GetLevel())
 	zerolog.SetGlobalLevel(level)
-	zerolog.TimeFieldFormat = "2006-01-02 15:04:05.000"
+	setTimeAndKeys(config)
 
 	// 构造输出 writer
 	var writers []io.Writer
+	var rawFileWriter io.Writer
 
-	if config.Output == "console" || config.Output == "both" {
-		// 彩色控制台输出
-		consoleWriter := zerolog.ConsoleWriter{
-			Out:        os.Stdout,
-			TimeFormat: zerolog.TimeFieldFormat,
-		}
-		writers = append(writers, consoleWriter)
+	if config.ConsoleEnabled() {
+		writers = append(writers, newConsoleOutput(config))
 	}
 
-	if config.Output == "file" || config.Output == "both" {
+	if config.GetSink() != "" {
 		// 文件滚动输出
-		fileWriter := newFileWriter(config)
+		rawFileWriter = newFileWriter(config)
+		fileWriter := rawFileWriter
+		if config.FileEncoding == logmgr.EncodingLogfmt {
+			fileWriter = newLogfmtWriter(fileWriter)
+		}
 		writers = append(writers, fileWriter)
 	}
 
-	if len(writers) == 0 {
+	fr, err := config.NewFlightRecorder(rawFileWriter)
+	if err != nil {
+		panic("创建飞行记录器失败: " + err.Error())
+	}
+	logmgr.SetFlightRecorder(fr)
+	debugLogger = nil
+	if fr == nil && len(writers) == 0 {
 		// 如果没有输出目标，直接丢弃
-		log.Logger = zerolog.Nop()
+		logbridge.InstallZerolog(zerolog.Nop(), logbridge.SlogHandlerFromZerolog(zerolog.Nop(), false))
+		logmgr.SetSync(nil)
 		return
 	}
 
-	// 合并多个输出
-	multiWriter := io.MultiWriter(writers...)
-	log.Logger = zerolog.New(multiWriter).With().Timestamp().Logger()
+	var output io.Writer = io.MultiWriter(writers...)
+	if config.Metrics && len(writers) > 0 {
+		output = metricsWriter{out: output}
+	}
+	frLevel := getLogLevel(config.GetFlightRecorderLevel())
+	sampler := config.NewSampler()
+	stack := newStackHook(config)
+	// newBase 创建输出不低于 outLevel 的 logger。开启飞行记录器时 logger 放开到 FlightRecorderLevel，
+	// 低于 outLevel 的事件只写入飞行记录器
+	newBase := func(outLevel zerolog.Level, hooks ...zerolog.Hook) zerolog.Logger {
+		out, loggerLevel := output, outLevel
+		if fr != nil {
+			live := outLevel
+			if len(writers) == 0 {
+				live = zerolog.Disabled
+			}
+			if frLevel < outLevel {
+				out = levelWriter{out: output, level: outLevel}
+				loggerLevel = frLevel
+			}
+			// 先写入飞行记录器: error 事件触发的转储写在该事件之前
+			out = zerolog.MultiLevelWriter(
+				flightWriter{fr: fr, level: frLevel, live: live, logfmt: config.FileEncoding == logmgr.EncodingLogfmt},
+				out)
+		}
+		base := zerolog.New(out).Level(loggerLevel).With().Timestamp().Logger().Hook(hooks...)
+		if sampler != nil {
+			// 采样丢弃的事件不会编码，飞行记录器同样不会保留
+			base = base.Hook(samplingHook{sampler: sampler})
+		}
+		return base.Hook(stack)
+	}
+	withCaller := func(base zerolog.Logger) zerolog.Logger {
+		if config.DisableCaller {
+			return base
+		}
+		return base.With().Caller().Logger()
+	}
+
+	// 按请求开启 debug 时，未开启飞行记录器由 debugHook 根据事件的 context 丢弃低于配置级别的事件；
+	// 开启飞行记录器时低于配置级别的事件需要写入飞行记录器，改为由 Ctx 选择单独的 debug logger
+	escalate := config.ContextDebug && level > zerolog.DebugLevel
+	var base zerolog.Logger
+	var h slog.Handler
+	switch {
+	case escalate && fr == nil:
+		base = newBase(zerolog.DebugLevel, debugHook{level: level})
+		h = logbridge.SlogHandlerFromZerolog(base, !config.DisableCaller)
+	case escalate:
+		base = newBase(level)
+		debugBase := newBase(zerolog.DebugLevel)
+		debug := withCaller(debugBase)
+		debugLogger = &debug
+		h = logbridge.SlogHandlerFromZerologDebug(base, debugBase, !config.DisableCaller)
+	default:
+		base = newBase(level)
+		h = logbridge.SlogHandlerFromZerolog(base, !config.DisableCaller)
+	}
+	// 各 logger 自身设置了级别，全局级别只放开到其中最低的级别
+	global := base.GetLevel()
+	if debugLogger != nil && debugLogger.GetLevel() < global {
+		global = debugLogger.GetLevel()
+	}
+	zerolog.SetGlobalLevel(global)
+
+	// 设置全局 logger，并将标准库 log、slog、zap 路由到 zerolog
+	logbridge.InstallZerolog(withCaller(base), h)
+	logmgr.SetSync(nil) // writer 直接写入，无需刷新
+}
+
+// getLogLevel 将 logmgr 级别转换为 zerolog 级别
+func getLogLevel(level logmgr.Level) zerolog.Level {
+	switch level {
+	case logmgr.TraceLevel:
+		return zerolog.TraceLevel
+	case logmgr.DebugLevel:
+		return zerolog.DebugLevel
+	case logmgr.InfoLevel:
+		return zerolog.InfoLevel
+	case logmgr.WarnLevel:
+		return zerolog.WarnLevel
+	case logmgr.ErrorLevel:
+		return zerolog.ErrorLevel
+	case logmgr.PanicLevel:
+		return zerolog.PanicLevel
+	case logmgr.FatalLevel:
+		return zerolog.FatalLevel
+	default:
+		return zerolog.InfoLevel
+	}
+}
+
+// setTimeAndKeys 设置时间格式、时区和内置字段名
+func setTimeAndKeys(config logmgr.LogConfig) {
+	timeEnc, err := config.NewTimeEncoder()
+	if err != nil {
+		panic("日志时间配置无效: " + err.Error())
+	}
+	if timeEnc.Numeric() {
+		switch timeEnc.Unit() {
+		case time.Second:
+			zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
+		case time.Millisecond:
+			zerolog.TimeFieldFormat = zerolog.TimeFormatUnixMs
+		case time.Microsecond:
+			zerolog.TimeFieldFormat = zerolog.TimeFormatUnixMicro
+		default:
+			zerolog.TimeFieldFormat = zerolog.TimeFormatUnixNano
+		}
+	} else {
+		zerolog.TimeFieldFormat = timeEnc.Layout()
+	}
+	loc := timeEnc.Location()
+	zerolog.TimestampFunc = func() time.Time {
+		return time.Now().In(loc)
+	}
+
+	keys := config.FieldKeys(logmgr.FieldKeys{
+		Time:       "time",
+		Level:      "level",
+		Message:    "message",
+		Caller:     "caller",
+		Stacktrace: logmgr.StacktraceKey,
+	})
+	zerolog.TimestampFieldName = keys.Time
+	zerolog.LevelFieldName = keys.Level
+	zerolog.MessageFieldName = keys.Message
+	zerolog.CallerFieldName = keys.Caller
+	stacktraceKey = keys.Stacktrace
+
+	// 级别取值
+	schema := config.GetSchema()
+	zerolog.LevelFieldMarshalFunc = func(l zerolog.Level) string {
+		if schema.Levels == nil {
+			return l.String()
+		}
+		return schema.LevelName(toLevel(l))
+	}
+
+	// 调用位置格式
+	callerFormat := config.CallerFormat
+	zerolog.CallerMarshalFunc = func(pc uintptr, file string, line int) string {
+		var function string
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			function = fn.Name()
+		}
+		return logmgr.FormatCaller(callerFormat, file, line, function)
+	}
 }
 
-// newFileWriter 创建滚动文件写入器
+// newConsoleOutput 创建控制台输出，默认使用带颜色的模板格式
+func newConsoleOutput(config logmgr.LogConfig) io.Writer {
+	switch config.ConsoleEncoding {
+	case logmgr.EncodingJSON:
+		return config.WrapSink(logmgr.SinkConsole, os.Stdout)
+	case logmgr.EncodingLogfmt:
+		return newLogfmtWriter(config.WrapSink(logmgr.SinkConsole, os.Stdout))
+	}
+	formatter, err := config.NewFormatter()
+	if err != nil {
+		panic("创建控制台格式失败: " + err.Error())
+	}
+	timeEnc, _ := config.NewTimeEncoder()
+	return newConsoleWriter(config.WrapSink(logmgr.SinkConsole, colorable.NewColorableStdout()), formatter, timeEnc, config.GetSchema())
+}
+
+// newFileWriter 创建滚动文件写入器，输出到内存或套接字时返回对应的 writer
 func newFileWriter(config logmgr.LogConfig) io.Writer {
-	// 确保日志目录存在
-	dir := filepath.Dir(config.FilePath)
-	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
-		panic("创建日志目录失败: " + err.Error())
+	switch config.GetSink() {
+	case logmgr.SinkMemory:
+		return config.WrapSink(logmgr.SinkMemory, config.CaptureWriter())
+	case logmgr.SinkSocket:
+		w, err := config.NewSocketWriter()
+		if err != nil {
+			panic("创建日志套接字失败: " + err.Error())
+		}
+		return config.WrapSink(logmgr.SinkSocket, w)
 	}
 
-	// 使用 lumberjack 实现滚动
-	return &lumberjack.Logger{
-		Filename:   config.FilePath,
-		MaxSize:    config.MaxSize,
-		MaxBackups: config.MaxBackups,
-		MaxAge:     config.MaxAge,
-		Compress:   config.Compress,
+	// 按大小轮转，按配置创建目录和设置权限，配置了密钥时加密
+	w, err := config.NewFileWriter()
+	if err != nil {
+		panic("创建日志文件失败: " + err.Error())
 	}
+	return config.WrapSink(logmgr.SinkFile, w)
 }
@@ -0,0 +1,33 @@
+package zerologmgr
+
+import (
+	"testing"
+
+	"github.com/52debug/go-box/log/logmgr"
+	"github.com/rs/zerolog"
+)
+
+func TestLevelMapping(t *testing.T) {
+	tests := []struct {
+		level   logmgr.Level
+		zerolog zerolog.Level
+	}{
+		{logmgr.TraceLevel, zerolog.TraceLevel},
+		{logmgr.DebugLevel, zerolog.DebugLevel},
+		{logmgr.InfoLevel, zerolog.InfoLevel},
+		{logmgr.WarnLevel, zerolog.WarnLevel},
+		{logmgr.ErrorLevel, zerolog.ErrorLevel},
+		{logmgr.PanicLevel, zerolog.PanicLevel},
+		{logmgr.FatalLevel, zerolog.FatalLevel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			if got := getLogLevel(tt.level); got != tt.zerolog {
+				t.Fatalf("getLogLevel(%v) = %v，期望 %v", tt.level, got, tt.zerolog)
+			}
+			if got := toLevel(tt.zerolog); got != tt.level {
+				t.Fatalf("toLevel(%v) = %v，期望 %v", tt.zerolog, got, tt.level)
+			}
+		})
+	}
+}